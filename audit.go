@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SnapshotFolder holds the last nilai of every class, inside the jurusan-semester JSON folder
+const SnapshotFolder = ".snapshot"
+
+// Audit change kinds
+const (
+	AuditTambah = "tambah"
+	AuditHapus  = "hapus"
+	AuditUbah   = "ubah"
+)
+
+// nilaiSnapshot is the stored nilai of one class at a point in time
+type nilaiSnapshot struct {
+	Waktu time.Time `json:"waktu"`
+	Nilai []Nilai   `json:"nilai"`
+}
+
+// AuditEntry is one added/removed student or one changed score of a class
+type AuditEntry struct {
+	WaktuLama time.Time `json:"waktu_lama"`
+	WaktuBaru time.Time `json:"waktu_baru"`
+	KodeMK    string    `json:"kodemk"`
+	Namamk    string    `json:"namamk"`
+	Kelas     string    `json:"kelas"`
+	Namadosen string    `json:"namadosen"`
+	Jenis     string    `json:"jenis"`
+	NIM       string    `json:"nim"`
+	Nama      string    `json:"nama"`
+	Komponen  string    `json:"komponen,omitempty"`
+	Lama      string    `json:"lama,omitempty"`
+	Baru      string    `json:"baru,omitempty"`
+}
+
+// auditLog collects the changes of a jurusan-semester run.
+// Safe for use from multiple goroutines.
+type auditLog struct {
+	mu      sync.Mutex
+	folder  string
+	entries []AuditEntry
+}
+
+func newAuditLog(folderJSON string) (*auditLog, error) {
+	folder := filepath.Join(folderJSON, SnapshotFolder)
+	if err := os.MkdirAll(folder, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("gagal buat folder snapshot: %w", err)
+	}
+	return &auditLog{folder: folder}, nil
+}
+
+func (a *auditLog) snapshotPath(mk MataKuliah) string {
+	return filepath.Join(a.folder, sanitizeFilename(mk.KodeMK+" R"+mk.Kelas)+".json")
+}
+
+// Record compares nilai with the previous snapshot of mk, collects the
+// differences and stores nilai as the new snapshot
+func (a *auditLog) Record(mk MataKuliah, nilai []Nilai) error {
+	path := a.snapshotPath(mk)
+	now := time.Now()
+
+	if data, err := os.ReadFile(path); err == nil {
+		var prev nilaiSnapshot
+		if err := json.Unmarshal(data, &prev); err != nil {
+			return fmt.Errorf("gagal parsing snapshot %s: %w", path, err)
+		}
+		entries := diffNilai(prev.Nilai, nilai)
+		for i := range entries {
+			entries[i].WaktuLama, entries[i].WaktuBaru = prev.Waktu, now
+			entries[i].KodeMK, entries[i].Namamk = mk.KodeMK, mk.Namamk
+			entries[i].Kelas, entries[i].Namadosen = mk.Kelas, mk.Namadosen
+		}
+		a.mu.Lock()
+		a.entries = append(a.entries, entries...)
+		a.mu.Unlock()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("gagal baca snapshot %s: %w", path, err)
+	}
+
+	return writeJSON(path, nilaiSnapshot{Waktu: now, Nilai: nilai})
+}
+
+// Baseline stores nilai as the snapshot of mk if it has none yet, so the
+// first change of a class scraped before auditing existed is still diffed
+func (a *auditLog) Baseline(mk MataKuliah, nilai []Nilai) error {
+	path := a.snapshotPath(mk)
+	if _, err := os.Stat(path); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	return writeJSON(path, nilaiSnapshot{Waktu: time.Now(), Nilai: nilai})
+}
+
+// diffNilai lists added and removed students and every changed field per NIM
+func diffNilai(old, cur []Nilai) []AuditEntry {
+	oldByNIM := make(map[string]Nilai, len(old))
+	for _, n := range old {
+		oldByNIM[n.NIM] = n
+	}
+	curByNIM := make(map[string]Nilai, len(cur))
+	for _, n := range cur {
+		curByNIM[n.NIM] = n
+	}
+
+	var entries []AuditEntry
+	for _, n := range cur {
+		o, ok := oldByNIM[n.NIM]
+		if !ok {
+			entries = append(entries, AuditEntry{Jenis: AuditTambah, NIM: n.NIM, Nama: n.Nama})
+			continue
+		}
+		for _, f := range nilaiFields(o, n) {
+			if f.lama != f.baru {
+				entries = append(entries, AuditEntry{Jenis: AuditUbah, NIM: n.NIM, Nama: n.Nama, Komponen: f.nama, Lama: f.lama, Baru: f.baru})
+			}
+		}
+	}
+	for _, o := range old {
+		if _, ok := curByNIM[o.NIM]; !ok {
+			entries = append(entries, AuditEntry{Jenis: AuditHapus, NIM: o.NIM, Nama: o.Nama})
+		}
+	}
+	return entries
+}
+
+type nilaiField struct{ nama, lama, baru string }
+
+func nilaiFields(o, n Nilai) []nilaiField {
+	return []nilaiField{
+		{"nil_angka", o.NilAngka, n.NilAngka},
+		{"nil_huruf", o.NilHuruf, n.NilHuruf},
+		{"hadir", o.Hadir, n.Hadir},
+		{"projek", o.Projek, n.Projek},
+		{"quiz", o.Quiz, n.Quiz},
+		{"tugas", o.Tugas, n.Tugas},
+		{"uts", o.UTS, n.UTS},
+		{"uas", o.UAS, n.UAS},
+	}
+}
+
+// Entries returns the collected changes sorted by class and NIM
+func (a *auditLog) Entries() []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := append([]AuditEntry(nil), a.entries...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].KodeMK != list[j].KodeMK {
+			return list[i].KodeMK < list[j].KodeMK
+		}
+		if list[i].Kelas != list[j].Kelas {
+			return list[i].Kelas < list[j].Kelas
+		}
+		return list[i].NIM < list[j].NIM
+	})
+	return list
+}
+
+// writeAudit writes the changes as a timestamped "Audit Nilai" report in an "Audit" subfolder
+func writeAudit(cfg *Config, out mkOutput, entries []AuditEntry, at time.Time) ([]string, error) {
+	auditOut := mkOutput{
+		folderJSON:  filepath.Join(out.folderJSON, "Audit"),
+		folderExcel: filepath.Join(out.folderExcel, "Audit"),
+	}
+	for _, dir := range []string{auditOut.folderJSON, auditOut.folderExcel} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := [][]interface{}{{"Waktu Lama", "Waktu Baru", "Kode MK", "Nama MK", "Kelas", "Dosen", "Jenis", "NIM", "Nama", "Komponen", "Lama", "Baru"}}
+	for _, e := range entries {
+		rows = append(rows, []interface{}{
+			e.WaktuLama.Format("2006-01-02 15:04:05"), e.WaktuBaru.Format("2006-01-02 15:04:05"),
+			e.KodeMK, e.Namamk, e.Kelas, e.Namadosen, e.Jenis, e.NIM, e.Nama, e.Komponen, e.Lama, e.Baru,
+		})
+	}
+	name := "Audit Nilai " + at.Format("2006-01-02 150405")
+	return writeReport(cfg, auditOut, name, entries, sheetTable{Name: "Audit", Rows: rows})
+}
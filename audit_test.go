@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestAuditBaselineBeforeFirstChange(t *testing.T) {
+	a, err := newAuditLog(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mk := MataKuliah{KodeMK: "AK101", Kelas: "A", Namamk: "Pengantar Akuntansi"}
+	lama := []Nilai{{NIM: "1", Nama: "Ani", NilAngka: "80"}}
+
+	// MK tidak berubah sejak sebelum audit ada: hanya baseline yang disimpan
+	if err := a.Baseline(mk, lama); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Baseline(mk, []Nilai{{NIM: "1", Nama: "Ani", NilAngka: "10"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Entries()) != 0 {
+		t.Fatalf("baseline recorded changes: %+v", a.Entries())
+	}
+
+	if err := a.Record(mk, []Nilai{{NIM: "1", Nama: "Ani", NilAngka: "85"}}); err != nil {
+		t.Fatal(err)
+	}
+	entries := a.Entries()
+	if len(entries) != 1 || entries[0].Komponen != "nil_angka" || entries[0].Lama != "80" || entries[0].Baru != "85" {
+		t.Errorf("entries = %+v, want nil_angka 80 -> 85", entries)
+	}
+}
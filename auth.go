@@ -7,15 +7,15 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 func handleAuthentication(scraper *Scraper) error {
-	if err := loadCookie(); err != nil {
+	if err := scraper.loadCookie(); err != nil {
 		logf(LogError, "Gagal load cookie: %v", err)
 	}
-	scraper.cookie = cookie
 
 	if scraper.cookie != "" && scraper.IsSessionValid() {
 		log(LogInfo, "Cookie masih valid, skip login")
@@ -24,9 +24,10 @@ func handleAuthentication(scraper *Scraper) error {
 		log(LogInfo, "Login ulang...")
 		if !scraper.Login(scraper.config.Username, scraper.config.Password) {
 			logf(LogError, "login gagal")
+			notifyWebhooks(scraper.config, EventLoginFailed, map[string]string{"error": "login gagal"})
 			return fmt.Errorf("login gagal")
 		}
-		if err := saveCookie(); err != nil {
+		if err := scraper.saveCookie(); err != nil {
 			logf(LogWarn, "Gagal simpan cookie: %v", err)
 		}
 	}
@@ -34,7 +35,10 @@ func handleAuthentication(scraper *Scraper) error {
 }
 
 func (s *Scraper) Login(username, password string) bool {
-	res, err := s.client.Get(s.baseURL + IndexEndpoint)
+	site := s.config.Deployment
+	start := time.Now()
+	res, err := s.client.Get(s.baseURL + site.Endpoints.Index)
+	observeRequest(site.Endpoints.Index, start, res, err)
 	if err != nil {
 		return false
 	}
@@ -42,7 +46,7 @@ func (s *Scraper) Login(username, password string) bool {
 
 	session := ""
 	for _, c := range res.Cookies() {
-		if c.Name == CookiePHPSESSID {
+		if c.Name == site.SessionCookie {
 			session = c.Value
 		}
 	}
@@ -54,34 +58,35 @@ func (s *Scraper) Login(username, password string) bool {
 	hideIP := getRandomIP()
 
 	data := url.Values{}
-	data.Set(FormUsername, username)
-	data.Set(FormPassword, password)
-	data.Set(FormValidation, hideValidation)
-	data.Set(FormHideValidation, hideValidation)
-	data.Set(FormHideIP, hideIP)
+	data.Set(site.Fields.Username, username)
+	data.Set(site.Fields.Password, password)
+	data.Set(site.Fields.Validation, hideValidation)
+	data.Set(site.Fields.HideValidation, hideValidation)
+	data.Set(site.Fields.HideIP, hideIP)
 
-	req, _ := http.NewRequest(POST, s.baseURL+LoginEndpoint, strings.NewReader(data.Encode()))
+	req, _ := http.NewRequest(POST, s.baseURL+site.Endpoints.Login, strings.NewReader(data.Encode()))
 	ua := userAgents[rand.Intn(len(userAgents))]
 	req.Header.Set(HeaderUserAgent, ua)
 	req.Header.Set(HeaderContentType, ContentTypeForm)
-	req.Header.Set(HeaderCookie, CookiePHPSESSID+"="+session)
+	req.Header.Set(HeaderCookie, site.SessionCookie+"="+session)
 	req.Header.Set(HeaderXRequestedWith, XMLHttpRequest)
 
+	start = time.Now()
 	resp, err := s.client.Do(req)
+	observeRequest(site.Endpoints.Login, start, resp, err)
 	if err != nil {
 		return false
 	}
 	defer resp.Body.Close()
 
 	for _, c := range resp.Cookies() {
-		if c.Name == CookiePHPSESSID {
-			s.cookie = CookiePHPSESSID + "=" + c.Value
-			cookie = s.cookie
+		if c.Name == site.SessionCookie {
+			s.cookie = site.SessionCookie + "=" + c.Value
 		}
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	return strings.Contains(string(body), `"success":true`)
+	return strings.Contains(string(body), site.LoginSuccess)
 }
 
 func generateValidation() string {
@@ -112,24 +117,30 @@ func getRandomIP() string {
 	return strings.TrimSpace(string(ip))
 }
 
-func loadCookie() error {
-	data, err := os.ReadFile(CookieFile)
+// loadCookie reads the session store of the profile into s
+func (s *Scraper) loadCookie() error {
+	path := s.config.CookieFile
+	data, err := os.ReadFile(path)
 	if err != nil {
 		// It's okay if the file doesn't exist, just return nil
 		if os.IsNotExist(err) {
 			return nil
 		}
-		return fmt.Errorf("gagal baca cookie.txt: %w", err)
+		return fmt.Errorf("gagal baca %s: %w", path, err)
 	}
-	cookie = string(data)
+	s.cookie = string(data)
 	log(LogInfo, "Cookie ditemukan!")
 	return nil
 }
 
 // --- Simpan cookie ke file ---
-func saveCookie() error {
-	if cookie != "" {
-		if err := os.WriteFile(CookieFile, []byte(cookie), 0644); err != nil {
+func (s *Scraper) saveCookie() error {
+	if s.cookie != "" {
+		path := s.config.CookieFile
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return fmt.Errorf("gagal buat folder %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(s.cookie), 0644); err != nil {
 			return fmt.Errorf("gagal simpan cookie: %w", err)
 		}
 	}
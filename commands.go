@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commands are the non-interactive modes selected by the first argument
+var commands = map[string]func(args []string) error{
+	"daemon":  runDaemon,
+	"email":   runEmail,
+	"ipk":     runIPK,
+	"retry":   runRetry,
+	"serve":   runServe,
+	"webhook": runWebhook,
+}
+
+func runCommand(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("perintah tidak dikenal: %s (tersedia: %s)", name, strings.Join(names, ", "))
+	}
+	return cmd(args)
+}
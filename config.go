@@ -2,33 +2,107 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// Output format values
+const (
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+	FormatODS   = "ods"
+)
+
 // Config holds the application configuration
 type Config struct {
-	BaseURL  string
-	Username string
-	Password string
+	BaseURL     string
+	Username    string
+	Password    string
+	JSONFormat  string
+	SheetFormat string
+
+	// GradeTolerance is the allowed difference between nil_angka and the recomputed grade
+	GradeTolerance float64
+
+	// IncludeUnpublished also scrapes MK whose cetak is not "1"
+	IncludeUnpublished bool
+
+	// GradeScales holds the letter-grade scales read from GRADE_SCALE_FILE
+	GradeScales *GradeScales
+
+	// ConfigFile is the config file that was read, empty when only the
+	// defaults and the environment are used
+	ConfigFile string
+
+	// Profile is the institution/account profile in use, empty for none
+	Profile string
+
+	// Output folders (under the output root) and local files. CookieFile is
+	// the session store of the SIAKAD login.
+	JSONFolder  string
+	ExcelFolder string
+	RunFolder   string
+	CookieFile  string
+	JurusanFile string
+
+	// Deployment is the endpoint and form-field profile of the SIAKAD
+	// version at BaseURL, chosen by DeploymentName
+	DeploymentName string
+	Deployment     Deployment
+	RegValue       string
+
+	// WorkerCount limits the MK scraped at the same time
+	WorkerCount int
+
+	ExcelHeaders ExcelHeaders
+
+	// DashboardUser and DashboardPassword protect the serve mode; both empty
+	// leaves it open
+	DashboardUser     string
+	DashboardPassword string
+
+	// WebhookURLs receive run and failure events, signed with WebhookSecret
+	WebhookURLs    []string
+	WebhookSecret  string
+	WebhookRetries int
+
+	// SMTP settings of the email run report. EmailTo receives every jurusan,
+	// EmailToJurusan (EMAIL_TO_<kodejrs>) only the jurusan it is keyed by.
+	SMTPHost            string
+	SMTPPort            int
+	SMTPUser            string
+	SMTPPassword        string
+	SMTPFrom            string
+	EmailTo             []string
+	EmailToJurusan      map[string][]string
+	EmailAttachWorkbook bool
 }
 
-// LoadConfig loads configuration from environment variables or .env file
+// LoadConfig loads configuration from the config file, environment variables
+// or .env file
 func LoadConfig() (*Config, error) {
-	// Load .env jika ada
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("[WARN] .env tidak ditemukan, gunakan env sistem")
-	}
-
-	config := &Config{
-		BaseURL:  os.Getenv("BASE_URL"),
-		Username: os.Getenv("USER_SIAKAD"),
-		Password: os.Getenv("PASSWORD_SIAKAD"),
+	config, err := loadOptions()
+	if err != nil {
+		return nil, err
 	}
 
 	if config.BaseURL == "" {
-		return nil, fmt.Errorf("BASE_URL tidak ditemukan di .env atau env sistem")
+		return nil, fmt.Errorf("BASE_URL tidak ditemukan di .env, env sistem, siakad.base_url atau base_url profil")
+	}
+	if config.Profile != "" {
+		suffix := profileEnvSuffix(config.Profile)
+		if config.Username == "" {
+			return nil, fmt.Errorf("username profil %s tidak ditemukan: isi USER_SIAKAD_%s atau USER_SIAKAD di .env, atau profiles.%s.username", config.Profile, suffix, config.Profile)
+		}
+		if config.Password == "" {
+			return nil, fmt.Errorf("password profil %s tidak ditemukan: isi PASSWORD_SIAKAD_%s atau PASSWORD_SIAKAD di .env, atau profiles.%s.password", config.Profile, suffix, config.Profile)
+		}
+		return config, nil
 	}
 	if config.Username == "" {
 		return nil, fmt.Errorf("USER_SIAKAD tidak ditemukan di .env atau env sistem")
@@ -39,3 +113,149 @@ func LoadConfig() (*Config, error) {
 
 	return config, nil
 }
+
+// loadOptions loads the configuration without requiring SIAKAD credentials,
+// for commands that only work on files already scraped
+func loadOptions() (*Config, error) {
+	// Load .env jika ada
+	if err := godotenv.Load(); err != nil {
+		log(LogWarn, ".env tidak ditemukan, gunakan env sistem")
+	}
+
+	file, path, err := readFileConfig()
+	if err != nil {
+		return nil, err
+	}
+	invalid := func(err error) error {
+		if path != "" {
+			return fmt.Errorf("konfigurasi %s: %w", path, err)
+		}
+		return fmt.Errorf("konfigurasi: %w", err)
+	}
+	// urutan: default < file < profil < env
+	username, password := os.Getenv("USER_SIAKAD"), os.Getenv("PASSWORD_SIAKAD")
+	var p Profile
+	profile := selectedProfile()
+	if profile != "" {
+		if p, err = file.applyProfile(profile); err != nil {
+			return nil, invalid(err)
+		}
+		username, password = p.Username, p.Password
+	}
+	if err := file.applyEnv(); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		file.profileCookie(p)
+		// kredensial profil hanya boleh dikirim ke host profil itu
+		if p.BaseURL != "" {
+			file.SIAKAD.BaseURL = p.BaseURL
+		}
+	}
+	if err := file.validate(); err != nil {
+		return nil, invalid(err)
+	}
+	deployment, err := resolveDeployment(file.SIAKAD.Deployment, file.Deployments)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	config := &Config{
+		BaseURL:     file.SIAKAD.BaseURL,
+		Username:    username,
+		Password:    password,
+		JSONFormat:  file.Output.JSONFormat,
+		SheetFormat: file.Output.SheetFormat,
+
+		GradeTolerance:     file.Scrape.GradeTolerance,
+		IncludeUnpublished: file.Scrape.IncludeUnpublished,
+
+		ConfigFile:     path,
+		Profile:        profile,
+		JSONFolder:     underRoot(file.Output.Root, file.Output.JSONFolder),
+		ExcelFolder:    underRoot(file.Output.Root, file.Output.ExcelFolder),
+		RunFolder:      underRoot(file.Output.Root, RunFolder),
+		CookieFile:     file.Files.Cookie,
+		JurusanFile:    file.Files.Jurusan,
+		DeploymentName: file.SIAKAD.Deployment,
+		Deployment:     deployment,
+		RegValue:       file.SIAKAD.RegValue,
+		WorkerCount:    file.Scrape.WorkerCount,
+		ExcelHeaders:   file.ExcelHeaders,
+
+		DashboardUser:     os.Getenv("DASHBOARD_USER"),
+		DashboardPassword: os.Getenv("DASHBOARD_PASSWORD"),
+	}
+
+	scales, err := loadGradeScales(file.Files.GradeScale)
+	if err != nil {
+		return nil, err
+	}
+	config.GradeScales = scales
+
+	for _, u := range splitList(os.Getenv("WEBHOOK_URLS")) {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("WEBHOOK_URLS tidak valid: %s", u)
+		}
+		config.WebhookURLs = append(config.WebhookURLs, u)
+	}
+	config.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	retries, err := strconv.Atoi(getEnv("WEBHOOK_RETRIES", "3"))
+	if err != nil || retries < 1 {
+		return nil, fmt.Errorf("WEBHOOK_RETRIES tidak valid: %s", os.Getenv("WEBHOOK_RETRIES"))
+	}
+	config.WebhookRetries = retries
+
+	config.SMTPHost = os.Getenv("SMTP_HOST")
+	config.SMTPUser = os.Getenv("SMTP_USER")
+	config.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	config.SMTPFrom = getEnv("SMTP_FROM", config.SMTPUser)
+	port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil || port <= 0 {
+		return nil, fmt.Errorf("SMTP_PORT tidak valid: %s", os.Getenv("SMTP_PORT"))
+	}
+	config.SMTPPort = port
+	config.EmailTo = splitList(os.Getenv("EMAIL_TO"))
+	config.EmailToJurusan = map[string][]string{}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if kode, ok := strings.CutPrefix(key, "EMAIL_TO_"); ok && kode != "" {
+			if to := splitList(value); len(to) > 0 {
+				config.EmailToJurusan[kode] = to
+			}
+		}
+	}
+	attach, err := strconv.ParseBool(getEnv("EMAIL_ATTACH_WORKBOOK", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("EMAIL_ATTACH_WORKBOOK tidak valid: %s", os.Getenv("EMAIL_ATTACH_WORKBOOK"))
+	}
+	config.EmailAttachWorkbook = attach
+	if config.SMTPHost != "" && config.SMTPFrom == "" {
+		return nil, fmt.Errorf("SMTP_FROM wajib diisi jika SMTP_USER kosong")
+	}
+
+	if (config.DashboardUser == "") != (config.DashboardPassword == "") {
+		return nil, fmt.Errorf("DASHBOARD_USER dan DASHBOARD_PASSWORD harus diisi keduanya")
+	}
+
+	return config, nil
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getEnv returns the environment variable or def when it is empty
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
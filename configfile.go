@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is read when it exists and neither --config nor
+// CONFIG_FILE names another file
+const DefaultConfigFile = "config.yaml"
+
+// configFile is the path given with --config
+var configFile string
+
+// parseGlobalFlags reads the flags given before the command name and returns
+// the remaining arguments
+func parseGlobalFlags(args []string) []string {
+	fs := flag.NewFlagSet("scrapping", flag.ExitOnError)
+	fs.StringVar(&configFile, "config", "", "file konfigurasi YAML (default "+DefaultConfigFile+" atau CONFIG_FILE)")
+	fs.StringVar(&profileName, "profile", "", "profil institusi/akun dari profiles di file konfigurasi (atau SIAKAD_PROFILE)")
+	fs.Parse(args)
+	return fs.Args()
+}
+
+// ExcelHeaders are the column labels of the nilai worksheet
+type ExcelHeaders struct {
+	NIM       string `yaml:"nim"`
+	Nama      string `yaml:"nama"`
+	KodeMK    string `yaml:"kode_mk"`
+	NamaMK    string `yaml:"nama_mk"`
+	Semester  string `yaml:"semester"`
+	Kelas     string `yaml:"kelas"`
+	Angka     string `yaml:"angka"`
+	Huruf     string `yaml:"huruf"`
+	Kehadiran string `yaml:"kehadiran"`
+	Projek    string `yaml:"projek"`
+	Quiz      string `yaml:"quiz"`
+	Tugas     string `yaml:"tugas"`
+	UTS       string `yaml:"uts"`
+	UAS       string `yaml:"uas"`
+	KodePK    string `yaml:"kode_pk"`
+	NamaPK    string `yaml:"nama_pk"`
+	KodeProdi string `yaml:"kode_prodi"`
+	NamaProdi string `yaml:"nama_prodi"`
+}
+
+// Nilai returns the labels in column order
+func (h ExcelHeaders) Nilai() []string {
+	return []string{h.NIM, h.Nama, h.KodeMK, h.NamaMK, h.Semester, h.Kelas, h.Angka, h.Huruf, h.Kehadiran, h.Projek, h.Quiz, h.Tugas, h.UTS, h.UAS, h.KodePK, h.NamaPK, h.KodeProdi, h.NamaProdi}
+}
+
+// fileConfig is the layout of the config file. It starts from the defaults,
+// so a file only needs the keys it changes.
+type fileConfig struct {
+	SIAKAD       siakadSection         `yaml:"siakad"`
+	Deployments  map[string]Deployment `yaml:"deployments"`
+	Profiles     map[string]Profile    `yaml:"profiles"`
+	Output       outputSection         `yaml:"output"`
+	Files        filesSection          `yaml:"files"`
+	Scrape       scrapeSection         `yaml:"scrape"`
+	ExcelHeaders ExcelHeaders          `yaml:"excel_headers"`
+}
+
+type siakadSection struct {
+	BaseURL    string `yaml:"base_url"`
+	RegValue   string `yaml:"reg_value"`
+	Deployment string `yaml:"deployment"`
+}
+
+type outputSection struct {
+	Root        string `yaml:"root"`
+	JSONFolder  string `yaml:"json_folder"`
+	ExcelFolder string `yaml:"excel_folder"`
+	JSONFormat  string `yaml:"json_format"`
+	SheetFormat string `yaml:"sheet_format"`
+}
+
+type filesSection struct {
+	Cookie     string `yaml:"cookie"`
+	Jurusan    string `yaml:"jurusan"`
+	GradeScale string `yaml:"grade_scale"`
+}
+
+type scrapeSection struct {
+	WorkerCount        int     `yaml:"worker_count"`
+	GradeTolerance     float64 `yaml:"grade_tolerance"`
+	IncludeUnpublished bool    `yaml:"include_unpublished"`
+}
+
+func defaultFileConfig() fileConfig {
+	var f fileConfig
+	f.SIAKAD.RegValue = "REG"
+	f.SIAKAD.Deployment = DefaultDeployment
+	f.Output.JSONFolder = "nilai_json"
+	f.Output.ExcelFolder = "nilai_excel"
+	f.Output.JSONFormat = FormatJSON
+	f.Output.SheetFormat = FormatXLSX
+	f.Files.Cookie = "cookie.txt"
+	f.Files.Jurusan = "jurusan.json"
+	f.Files.GradeScale = "skala_nilai.json"
+	f.Scrape.WorkerCount = 5
+	f.Scrape.GradeTolerance = 0.5
+	f.ExcelHeaders = ExcelHeaders{
+		NIM:       "Nim",
+		Nama:      "Nama Mahasiswa",
+		KodeMK:    "Kode Mata Kuliah",
+		NamaMK:    "Nama Mata Kuliah",
+		Semester:  "Semester",
+		Kelas:     "Nama Kelas",
+		Angka:     "Angka",
+		Huruf:     "Huruf",
+		Kehadiran: "Aktivitas Partisipatif",
+		Projek:    "Hasil Proyek",
+		Quiz:      "Kognitif/ Pengetahuan Quiz",
+		Tugas:     "Kognitif/ Pengetahuan Tugas",
+		UTS:       "Kognitif/ Pengetahuan Ujian Tengah Semester",
+		UAS:       "Kognitif/ Pengetahuan Ujian Akhir Semester",
+		KodePK:    "Kode Prodi Mahasiswa",
+		NamaPK:    "Nama Prodi Mahasiswa",
+		KodeProdi: "Kode Prodi Kelas",
+		NamaProdi: "Nama Prodi Kelas",
+	}
+	return f
+}
+
+// readFileConfig reads the config file over the defaults. The file is
+// --config, else CONFIG_FILE, else DefaultConfigFile if it exists. Unknown
+// keys are rejected so a typo does not silently keep the default.
+func readFileConfig() (fileConfig, string, error) {
+	f := defaultFileConfig()
+	path := configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return f, "", nil
+		}
+		return f, "", fmt.Errorf("gagal baca file konfigurasi: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return f, "", fmt.Errorf("file konfigurasi %s tidak valid: %w", path, err)
+	}
+	return f, path, nil
+}
+
+// applyEnv lets environment variables (and .env) override the file
+func (f *fileConfig) applyEnv() error {
+	envString(&f.SIAKAD.BaseURL, "BASE_URL")
+	envString(&f.SIAKAD.RegValue, "REG_VALUE")
+	envString(&f.SIAKAD.Deployment, "SIAKAD_DEPLOYMENT")
+	envString(&f.Output.Root, "OUTPUT_ROOT")
+	envString(&f.Output.JSONFolder, "JSON_FOLDER")
+	envString(&f.Output.ExcelFolder, "EXCEL_FOLDER")
+	envString(&f.Output.JSONFormat, "JSON_FORMAT")
+	envString(&f.Output.SheetFormat, "SHEET_FORMAT")
+	envString(&f.Files.Cookie, "COOKIE_FILE")
+	envString(&f.Files.Jurusan, "JURUSAN_FILE")
+	envString(&f.Files.GradeScale, "GRADE_SCALE_FILE")
+	if err := envInt(&f.Scrape.WorkerCount, "WORKER_COUNT"); err != nil {
+		return err
+	}
+	if v := os.Getenv("GRADE_TOLERANCE"); v != "" {
+		tolerance, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("GRADE_TOLERANCE tidak valid: %s", v)
+		}
+		f.Scrape.GradeTolerance = tolerance
+	}
+	if v := os.Getenv("INCLUDE_UNPUBLISHED"); v != "" {
+		unpublished, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("INCLUDE_UNPUBLISHED tidak valid: %s", v)
+		}
+		f.Scrape.IncludeUnpublished = unpublished
+	}
+	f.Output.JSONFormat = strings.ToLower(f.Output.JSONFormat)
+	f.Output.SheetFormat = strings.ToLower(f.Output.SheetFormat)
+	return nil
+}
+
+// validate reports the first invalid value by its key in the file and the
+// environment variable that overrides it
+func (f *fileConfig) validate() error {
+	required := []struct{ key, value string }{
+		{"siakad.reg_value (REG_VALUE)", f.SIAKAD.RegValue},
+		{"output.json_folder (JSON_FOLDER)", f.Output.JSONFolder},
+		{"output.excel_folder (EXCEL_FOLDER)", f.Output.ExcelFolder},
+		{"files.cookie (COOKIE_FILE)", f.Files.Cookie},
+		{"files.jurusan (JURUSAN_FILE)", f.Files.Jurusan},
+		{"files.grade_scale (GRADE_SCALE_FILE)", f.Files.GradeScale},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			return fmt.Errorf("%s tidak boleh kosong", r.key)
+		}
+	}
+	if f.Output.JSONFormat != FormatJSON && f.Output.JSONFormat != FormatJSONL {
+		return fmt.Errorf("output.json_format (JSON_FORMAT) tidak valid: %s (pilih %s atau %s)", f.Output.JSONFormat, FormatJSON, FormatJSONL)
+	}
+	if f.Output.SheetFormat != FormatXLSX && f.Output.SheetFormat != FormatODS {
+		return fmt.Errorf("output.sheet_format (SHEET_FORMAT) tidak valid: %s (pilih %s atau %s)", f.Output.SheetFormat, FormatXLSX, FormatODS)
+	}
+	if f.Scrape.WorkerCount < 1 {
+		return fmt.Errorf("scrape.worker_count (WORKER_COUNT) minimal 1, bukan %d", f.Scrape.WorkerCount)
+	}
+	if f.Scrape.GradeTolerance < 0 {
+		return fmt.Errorf("scrape.grade_tolerance (GRADE_TOLERANCE) tidak boleh negatif: %g", f.Scrape.GradeTolerance)
+	}
+	for i, h := range f.ExcelHeaders.Nilai() {
+		if strings.TrimSpace(h) == "" {
+			return fmt.Errorf("excel_headers: label kolom ke-%d kosong", i+1)
+		}
+	}
+	return nil
+}
+
+func envString(dst *string, key string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func envInt(dst *int, key string) error {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s tidak valid: %s", key, v)
+		}
+		*dst = n
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configEnv are the variables loadOptions reads; they are cleared so the
+// environment of the test process does not leak in
+var configEnv = []string{
+	"CONFIG_FILE", "SIAKAD_PROFILE", "BASE_URL", "REG_VALUE", "SIAKAD_DEPLOYMENT",
+	"OUTPUT_ROOT", "JSON_FOLDER", "EXCEL_FOLDER", "JSON_FORMAT", "SHEET_FORMAT",
+	"COOKIE_FILE", "JURUSAN_FILE", "GRADE_SCALE_FILE", "WORKER_COUNT",
+	"GRADE_TOLERANCE", "INCLUDE_UNPUBLISHED", "USER_SIAKAD", "PASSWORD_SIAKAD",
+	"USER_SIAKAD_KAMPUS_A", "PASSWORD_SIAKAD_KAMPUS_A", "SMTP_HOST", "EMAIL_TO",
+	"DASHBOARD_USER", "DASHBOARD_PASSWORD", "WEBHOOK_URLS",
+}
+
+const testConfigFile = `
+siakad:
+  base_url: http://file.example
+output:
+  root: out
+  json_folder: json_file
+scrape:
+  worker_count: 2
+profiles:
+  kampus-a:
+    base_url: http://kampus-a.example
+    jurusan: jurusan-a.json
+    username: user-file
+  kampus-b:
+    output_root: /data/b
+    cookie: sesi-b.txt
+`
+
+// loadTestConfig runs loadOptions in an empty folder with the given config
+// file content (none if empty) and environment
+func loadTestConfig(t *testing.T, file string, env map[string]string) (*Config, error) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	for _, k := range configEnv {
+		t.Setenv(k, "")
+	}
+	configFile, profileName = "", ""
+	if file != "" {
+		if err := os.WriteFile(DefaultConfigFile, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	return loadOptions()
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		check func(t *testing.T, c *Config)
+	}{
+		{"defaults", "", nil, func(t *testing.T, c *Config) {
+			want(t, "JSONFolder", c.JSONFolder, "nilai_json")
+			want(t, "RunFolder", c.RunFolder, RunFolder)
+			want(t, "CookieFile", c.CookieFile, "cookie.txt")
+			want(t, "DeploymentName", c.DeploymentName, DefaultDeployment)
+			want(t, "Login endpoint", c.Deployment.Endpoints.Login, defaultDeployment().Endpoints.Login)
+			if c.WorkerCount != 5 {
+				t.Errorf("WorkerCount = %d, want 5", c.WorkerCount)
+			}
+		}},
+		{"file over defaults", testConfigFile, nil, func(t *testing.T, c *Config) {
+			want(t, "BaseURL", c.BaseURL, "http://file.example")
+			want(t, "JSONFolder", c.JSONFolder, filepath.Join("out", "json_file"))
+			want(t, "ExcelFolder", c.ExcelFolder, filepath.Join("out", "nilai_excel"))
+			want(t, "CookieFile", c.CookieFile, "cookie.txt")
+			if c.WorkerCount != 2 {
+				t.Errorf("WorkerCount = %d, want 2", c.WorkerCount)
+			}
+		}},
+		{"env over file", testConfigFile, map[string]string{"JSON_FOLDER": "json_env", "WORKER_COUNT": "7", "BASE_URL": "http://env.example"}, func(t *testing.T, c *Config) {
+			want(t, "BaseURL", c.BaseURL, "http://env.example")
+			want(t, "JSONFolder", c.JSONFolder, filepath.Join("out", "json_env"))
+			if c.WorkerCount != 7 {
+				t.Errorf("WorkerCount = %d, want 7", c.WorkerCount)
+			}
+		}},
+		{"profile over file", testConfigFile, map[string]string{"SIAKAD_PROFILE": "kampus-a", "PASSWORD_SIAKAD": "rahasia"}, func(t *testing.T, c *Config) {
+			want(t, "BaseURL", c.BaseURL, "http://kampus-a.example")
+			want(t, "JurusanFile", c.JurusanFile, "jurusan-a.json")
+			want(t, "JSONFolder", c.JSONFolder, filepath.Join("kampus-a", "json_file"))
+			want(t, "RunFolder", c.RunFolder, filepath.Join("kampus-a", RunFolder))
+			want(t, "CookieFile", c.CookieFile, filepath.Join("kampus-a", "cookie.txt"))
+			want(t, "Username", c.Username, "user-file")
+			want(t, "Password", c.Password, "rahasia")
+		}},
+		{"profile cookie and root", testConfigFile, map[string]string{"SIAKAD_PROFILE": "kampus-b"}, func(t *testing.T, c *Config) {
+			want(t, "BaseURL", c.BaseURL, "http://file.example")
+			want(t, "JSONFolder", c.JSONFolder, filepath.Join("/data/b", "json_file"))
+			want(t, "CookieFile", c.CookieFile, "sesi-b.txt")
+		}},
+		{"env over profile", testConfigFile, map[string]string{
+			"SIAKAD_PROFILE": "kampus-a",
+			"BASE_URL":       "http://env.example",
+			"OUTPUT_ROOT":    "/srv/out",
+			"JURUSAN_FILE":   "jurusan-env.json",
+		}, func(t *testing.T, c *Config) {
+			// base_url profil menang agar kredensialnya tidak dikirim ke host lain
+			want(t, "BaseURL", c.BaseURL, "http://kampus-a.example")
+			want(t, "JurusanFile", c.JurusanFile, "jurusan-env.json")
+			want(t, "JSONFolder", c.JSONFolder, filepath.Join("/srv/out", "json_file"))
+			want(t, "CookieFile", c.CookieFile, filepath.Join("/srv/out", "cookie.txt"))
+		}},
+		{"BASE_URL for a profile without base_url", testConfigFile, map[string]string{"SIAKAD_PROFILE": "kampus-b", "BASE_URL": "http://env.example"}, func(t *testing.T, c *Config) {
+			want(t, "BaseURL", c.BaseURL, "http://env.example")
+		}},
+		{"COOKIE_FILE over profile", testConfigFile, map[string]string{"SIAKAD_PROFILE": "kampus-b", "COOKIE_FILE": "env-cookie.txt"}, func(t *testing.T, c *Config) {
+			want(t, "CookieFile", c.CookieFile, "env-cookie.txt")
+		}},
+		{"profile credentials from env", testConfigFile, map[string]string{
+			"SIAKAD_PROFILE":           "kampus-a",
+			"USER_SIAKAD":              "user-umum",
+			"USER_SIAKAD_KAMPUS_A":     "user-env",
+			"PASSWORD_SIAKAD_KAMPUS_A": "pass-env",
+		}, func(t *testing.T, c *Config) {
+			want(t, "Username", c.Username, "user-env")
+			want(t, "Password", c.Password, "pass-env")
+		}},
+		{"profile credentials fall back", testConfigFile, map[string]string{
+			"SIAKAD_PROFILE":  "kampus-b",
+			"USER_SIAKAD":     "user-umum",
+			"PASSWORD_SIAKAD": "pass-umum",
+		}, func(t *testing.T, c *Config) {
+			want(t, "Username", c.Username, "user-umum")
+			want(t, "Password", c.Password, "pass-umum")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := loadTestConfig(t, tt.file, tt.env)
+			if err != nil {
+				t.Fatalf("loadOptions: %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{"unknown key", "scrape:\n  workers: 2\n", nil, "workers"},
+		{"unknown profile", testConfigFile, map[string]string{"SIAKAD_PROFILE": "kampus-c"}, "tersedia: kampus-a, kampus-b"},
+		{"no profiles", "", map[string]string{"SIAKAD_PROFILE": "kampus-a"}, "belum ada profiles"},
+		{"invalid env", "", map[string]string{"WORKER_COUNT": "banyak"}, "WORKER_COUNT"},
+		{"invalid file value", "scrape:\n  worker_count: 0\n", nil, "scrape.worker_count"},
+		{"invalid format", "", map[string]string{"SHEET_FORMAT": "csv"}, "output.sheet_format"},
+		{"unknown deployment", "", map[string]string{"SIAKAD_DEPLOYMENT": "kampus-x"}, "tersedia: default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tt.file, tt.env)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadOptions error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDeployment(t *testing.T) {
+	def := defaultDeployment()
+	d, err := resolveDeployment("kampus-b", map[string]Deployment{
+		"kampus-b": {Endpoints: Endpoints{Login: "/login/cek.php"}, Fields: FormFields{Username: "user"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want(t, "Login", d.Endpoints.Login, "/login/cek.php")
+	want(t, "Username field", d.Fields.Username, "user")
+	want(t, "Media", d.Endpoints.Media, def.Endpoints.Media)
+	want(t, "SessionCookie", d.SessionCookie, def.SessionCookie)
+	want(t, "RekapMKBody", d.RekapMKBody, def.RekapMKBody)
+	if len(d.LoggedOutMarkers) != len(def.LoggedOutMarkers) {
+		t.Errorf("LoggedOutMarkers = %q, want the default", d.LoggedOutMarkers)
+	}
+
+	for name, bad := range map[string]Deployment{
+		"endpoints.bobot":    {Endpoints: Endpoints{Bobot: "nilmk.php"}},
+		"logged_out_markers": {LoggedOutMarkers: []string{"login", " "}},
+	} {
+		if _, err := resolveDeployment("x", map[string]Deployment{"x": bad}); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("invalid %s: error = %v", name, err)
+		}
+	}
+}
+
+func want(t *testing.T, what, got, expected string) {
+	t.Helper()
+	if got != expected {
+		t.Errorf("%s = %q, want %q", what, got, expected)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ContentManifestFile is stored in every jurusan-semester JSON folder
+const ContentManifestFile = ".manifest.json"
+
+// ManifestEntry records what was last written for one class
+type ManifestEntry struct {
+	KodeMK  string    `json:"kodemk"`
+	Namamk  string    `json:"namamk"`
+	Kelas   string    `json:"kelas"`
+	Hash    string    `json:"hash"`
+	Files   []string  `json:"files"`
+	Updated time.Time `json:"updated"`
+}
+
+// ContentManifest maps every class of a jurusan-semester to the hash of its
+// nilai and bobot, so unchanged classes are not rewritten on the next run.
+// Safe for use from multiple goroutines.
+type ContentManifest struct {
+	mu      sync.Mutex
+	path    string
+	MK      map[string]ManifestEntry `json:"mk"`
+	changed []string
+}
+
+// loadContentManifest reads the manifest at path; a missing file yields an empty manifest
+func loadContentManifest(path string) (*ContentManifest, error) {
+	m := &ContentManifest{path: path, MK: map[string]ManifestEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("gagal baca %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("gagal parsing %s: %w", path, err)
+	}
+	if m.MK == nil {
+		m.MK = map[string]ManifestEntry{}
+	}
+	return m, nil
+}
+
+// contentHash hashes everything that ends up in the output files of a class
+func contentHash(mk MataKuliah, nilai []Nilai, bobot Bobot) string {
+	data, _ := json.Marshal(struct {
+		MataKuliah MataKuliah
+		Nilai      []Nilai
+		Bobot      Bobot
+	}{mk, nilai, bobot})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Unchanged reports whether mk was last written with hash to exactly the
+// files expected for the current output format, and those files still exist
+func (m *ContentManifest) Unchanged(mk MataKuliah, hash string, expected []string) bool {
+	m.mu.Lock()
+	entry, ok := m.MK[classKey(mk.KodeMK, mk.Kelas)]
+	m.mu.Unlock()
+	if !ok || entry.Hash != hash || len(entry.Files) != len(expected) {
+		return false
+	}
+	recorded := map[string]bool{}
+	for _, f := range entry.Files {
+		recorded[f] = true
+	}
+	for _, f := range expected {
+		if !recorded[f] {
+			return false
+		}
+		if _, err := os.Stat(f); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Update records that mk was written with hash to files
+func (m *ContentManifest) Update(mk MataKuliah, hash string, files []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := classKey(mk.KodeMK, mk.Kelas)
+	if old, ok := m.MK[key]; ok && old.Hash != hash {
+		m.changed = append(m.changed, fmt.Sprintf("%s R%s", mk.Namamk, mk.Kelas))
+	}
+	m.MK[key] = ManifestEntry{
+		KodeMK:  mk.KodeMK,
+		Namamk:  mk.Namamk,
+		Kelas:   mk.Kelas,
+		Hash:    hash,
+		Files:   files,
+		Updated: time.Now(),
+	}
+}
+
+// Changed returns the classes whose content differs from the previous run
+func (m *ContentManifest) Changed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := append([]string(nil), m.changed...)
+	sort.Strings(list)
+	return list
+}
+
+// Save writes the manifest back to its file
+func (m *ContentManifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0644)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func bits(values ...int) uint64 {
+	var set uint64
+	for _, v := range values {
+		set |= 1 << uint(v)
+	}
+	return set
+}
+
+func TestParseCronField(t *testing.T) {
+	minute := cronFields[0]
+	tests := []struct {
+		field string
+		want  uint64
+	}{
+		{"5", bits(5)},
+		{"1,2,30", bits(1, 2, 30)},
+		{"10-13", bits(10, 11, 12, 13)},
+		{"*/15", bits(0, 15, 30, 45)},
+		{"10-30/10", bits(10, 20, 30)},
+		{"50/5", bits(50, 55)},
+		{"0-2,58-59", bits(0, 1, 2, 58, 59)},
+	}
+	for _, tt := range tests {
+		got, err := parseCronField(tt.field, minute)
+		if err != nil {
+			t.Errorf("parseCronField(%q): %v", tt.field, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"10-5 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) accepted an invalid expression", expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// 2024-06-03 adalah hari Senin
+	monday := time.Date(2024, 6, 3, 2, 30, 0, 0, time.Local)
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"30 2 * * *", monday, true},
+		{"31 2 * * *", monday, false},
+		{"@daily", monday.Add(-150 * time.Minute), true},
+		{"@hourly", monday, false},
+		{"30 2 * * 1", monday, true},
+		{"30 2 * * 2", monday, false},
+		// Minggu boleh ditulis 0 atau 7
+		{"30 2 * * 7", monday.AddDate(0, 0, 6), true},
+		{"30 2 * * 0", monday.AddDate(0, 0, 6), true},
+		// tanggal saja
+		{"30 2 3 * *", monday, true},
+		{"30 2 4 * *", monday, false},
+		// tanggal dan hari sama-sama dibatasi: cukup salah satu cocok
+		{"30 2 15 * 1", monday, true},
+		{"30 2 3 * 5", monday, true},
+		{"30 2 15 * 5", monday, false},
+		// seperti Vixie cron, kolom yang diawali * (juga */n) tidak membatasi
+		{"30 2 2-30/2 * 5", monday, false},
+		{"30 2 */2 * 5", monday, false},
+		{"30 2 */2 * 1", monday, true},
+		{"30 2 3 * */2", monday, true},
+		{"30 2 4 * */2", monday, false},
+		{"30 2 * 7 *", monday, false},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Matches(tt.at); got != tt.want {
+			t.Errorf("%q Matches(%s) = %v, want %v", tt.expr, tt.at.Format("Mon 2006-01-02 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, 6, 3, 2, 30, 45, 0, time.Local)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 6, 3, 2, 31, 0, 0, time.Local)},
+		{"30 2 * * *", time.Date(2024, 6, 4, 2, 30, 0, 0, time.Local)},
+		{"0 0 1 * *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local)},
+		{"0 8 * * 6", time.Date(2024, 6, 8, 8, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		got, ok := c.Next(from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%q Next = %v, %v; want %v", tt.expr, got, ok, tt.want)
+		}
+	}
+
+	c, err := parseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Next(from); ok {
+		t.Error("31 Februari should never fire")
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// ScheduleFile lists the jobs of the daemon mode, see jadwal.example.json
+const ScheduleFile = "jadwal.json"
+
+// Schedule is a daemon job, submitted whenever Cron matches
+type Schedule struct {
+	Name string `json:"name"`
+	Cron string `json:"cron"`
+	ScrapeSpec
+
+	cron    *cronSchedule
+	lastJob string
+}
+
+// loadSchedules reads and validates the schedule file
+func loadSchedules(config *Config, path string) ([]*Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal baca %s: %w", path, err)
+	}
+	var schedules []*Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("gagal parsing %s: %w", path, err)
+	}
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("tidak ada jadwal di %s", path)
+	}
+
+	names := map[string]bool{}
+	for i, s := range schedules {
+		if s.Name == "" {
+			return nil, fmt.Errorf("jadwal ke-%d di %s tidak punya name", i+1, path)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("nama jadwal %s dipakai lebih dari sekali", s.Name)
+		}
+		names[s.Name] = true
+
+		if s.cron, err = parseCron(s.Cron); err != nil {
+			return nil, fmt.Errorf("jadwal %s: %w", s.Name, err)
+		}
+		if s.Semester == "" {
+			s.Semester = SemesterCurrent
+		}
+		if _, err := s.resolve(config); err != nil {
+			return nil, fmt.Errorf("jadwal %s: %w", s.Name, err)
+		}
+	}
+	return schedules, nil
+}
+
+// runDaemon submits the scheduled jobs on a single SIAKAD session until
+// interrupted. A schedule is skipped while its previous job is still queued
+// or running, so runs of the same job never overlap.
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	path := fs.String("jadwal", ScheduleFile, "file jadwal (JSON)")
+	keepAlive := fs.Duration("keepalive", 10*time.Minute, "interval cek sesi SIAKAD di antara job")
+	fs.Parse(args)
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("gagal load konfigurasi: %w", err)
+	}
+	schedules, err := loadSchedules(config, *path)
+	if err != nil {
+		return err
+	}
+	scraper := NewScraper(config)
+	manager := newJobManager(scraper)
+	if err := manager.authenticate(); err != nil {
+		return err
+	}
+	logf(LogInfo, "Login Sebagai: %s", config.Username)
+
+	now := time.Now()
+	for _, s := range schedules {
+		if next, ok := s.cron.Next(now); ok {
+			logf(LogInfo, "Jadwal %s (%s): %s semester %s, berikutnya %s", s.Name, s.Cron, s.Mode, s.Semester, next.Format("2006-01-02 15:04"))
+		} else {
+			logf(LogWarn, "Jadwal %s (%s) tidak akan pernah berjalan", s.Name, s.Cron)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go manager.KeepAlive(ctx, *keepAlive)
+
+	for {
+		tick := time.Now().Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(time.Until(tick))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logf(LogInfo, "Daemon berhenti, menunggu job yang berjalan selesai...")
+			manager.Shutdown()
+			return nil
+		case <-timer.C:
+		}
+		for _, s := range schedules {
+			if s.cron.Matches(tick) {
+				triggerSchedule(manager, s)
+			}
+		}
+	}
+}
+
+func triggerSchedule(manager *JobManager, s *Schedule) {
+	if s.lastJob != "" {
+		if job, ok := manager.Get(s.lastJob); ok && (job.Status == JobQueued || job.Status == JobRunning) {
+			logf(LogWarn, "Jadwal %s dilewati: job %s masih %s", s.Name, job.ID, job.Status)
+			return
+		}
+	}
+	job, err := manager.Submit(s.ScrapeSpec, s.Name)
+	if err != nil {
+		logf(LogError, "Jadwal %s gagal dijalankan: %v", s.Name, err)
+		return
+	}
+	s.lastJob = job.ID
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionCookie holds the dashboard login token
+const (
+	SessionCookie = "scraper_session"
+	SessionTTL    = 12 * time.Hour
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// fileRoots are the output folders the dashboard can browse
+func fileRoots(config *Config) map[string]string {
+	return map[string]string{
+		"json":  config.JSONFolder,
+		"excel": config.ExcelFolder,
+	}
+}
+
+// FileEntry is one item of a folder listing
+type FileEntry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Dir      bool      `json:"dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// dashboardHandler serves the embedded web UI, the login endpoints and the
+// API (guarded by the login when it is configured)
+func dashboardHandler(manager *JobManager, auth *toolAuth) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/login", auth.login)
+	mux.HandleFunc("POST /auth/logout", auth.logout)
+	mux.HandleFunc("GET /auth/me", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]bool{"login": auth.allowed(r), "required": auth.enabled()})
+	})
+	mux.Handle("/api/", auth.require(apiHandler(manager)))
+
+	static, _ := fs.Sub(webFiles, "web")
+	mux.Handle("/", http.FileServerFS(static))
+	return mux
+}
+
+// handleFiles lists a folder of an output root as JSON, or downloads a file
+func handleFiles(config *Config, w http.ResponseWriter, r *http.Request) {
+	dir, ok := fileRoots(config)[r.PathValue("root")]
+	if !ok {
+		respondError(w, http.StatusNotFound, fmt.Errorf("folder %s tidak dikenal", r.PathValue("root")))
+		return
+	}
+	name := strings.TrimSuffix(r.PathValue("path"), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("path tidak valid: %s", name))
+		return
+	}
+	if hiddenPath(name) {
+		respondError(w, http.StatusNotFound, fmt.Errorf("%s tidak ditemukan", name))
+		return
+	}
+
+	fsys := os.DirFS(dir)
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		respondError(w, http.StatusNotFound, fmt.Errorf("%s tidak ditemukan", name))
+		return
+	}
+	if !info.IsDir() {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(name)))
+		http.ServeFileFS(w, r, fsys, name)
+		return
+	}
+
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	list := []FileEntry{}
+	for _, e := range entries {
+		if hiddenPath(e.Name()) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, FileEntry{
+			Name:     e.Name(),
+			Path:     path.Join(name, e.Name()),
+			Dir:      e.IsDir(),
+			Size:     fi.Size(),
+			Modified: fi.ModTime(),
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Dir && !list[j].Dir })
+	respondJSON(w, http.StatusOK, list)
+}
+
+// hiddenPath reports whether a segment of the slash-separated name starts
+// with a dot. Hidden files (the content manifest, snapshots) are bookkeeping,
+// not results, and are neither listed nor served.
+func hiddenPath(name string) bool {
+	if name == "." {
+		return false
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if strings.HasPrefix(seg, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// toolAuth is the login of the dashboard itself, separate from the SIAKAD
+// account. API clients may use HTTP basic auth with the same credentials.
+type toolAuth struct {
+	user     string
+	password string
+
+	mu       sync.Mutex
+	sessions map[string]time.Time // token -> kedaluwarsa
+}
+
+func newToolAuth(config *Config) *toolAuth {
+	return &toolAuth{user: config.DashboardUser, password: config.DashboardPassword, sessions: map[string]time.Time{}}
+}
+
+func (a *toolAuth) enabled() bool {
+	return a.password != ""
+}
+
+func (a *toolAuth) check(user, password string) bool {
+	u := subtle.ConstantTimeCompare([]byte(user), []byte(a.user))
+	p := subtle.ConstantTimeCompare([]byte(password), []byte(a.password))
+	return u&p == 1
+}
+
+// allowed reports whether r carries a valid session or basic auth
+func (a *toolAuth) allowed(r *http.Request) bool {
+	if !a.enabled() {
+		return true
+	}
+	if user, password, ok := r.BasicAuth(); ok {
+		return a.check(user, password)
+	}
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	exp, ok := a.sessions[c.Value]
+	if ok && time.Now().After(exp) {
+		delete(a.sessions, c.Value)
+		return false
+	}
+	return ok
+}
+
+func (a *toolAuth) require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.allowed(r) {
+			respondError(w, http.StatusUnauthorized, fmt.Errorf("login diperlukan"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *toolAuth) login(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("body tidak valid: %w", err))
+		return
+	}
+	if !a.enabled() {
+		respondJSON(w, http.StatusOK, map[string]bool{"login": true})
+		return
+	}
+	if !a.check(body.Username, body.Password) {
+		logf(LogWarn, "Login dashboard gagal untuk %q dari %s", body.Username, r.RemoteAddr)
+		respondError(w, http.StatusUnauthorized, fmt.Errorf("username atau password salah"))
+		return
+	}
+
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	now := time.Now()
+	exp := now.Add(SessionTTL)
+	a.mu.Lock()
+	a.pruneSessions(now)
+	a.sessions[token] = exp
+	a.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  exp,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	respondJSON(w, http.StatusOK, map[string]bool{"login": true})
+}
+
+// pruneSessions drops the sessions that expired without a logout. Called
+// with a.mu held on every login, so the map stays bounded by the logins of
+// one SessionTTL.
+func (a *toolAuth) pruneSessions(now time.Time) {
+	for token, exp := range a.sessions {
+		if now.After(exp) {
+			delete(a.sessions, token)
+		}
+	}
+}
+
+func (a *toolAuth) logout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(SessionCookie); err == nil {
+		a.mu.Lock()
+		delete(a.sessions, c.Value)
+		a.mu.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: "", Path: "/", MaxAge: -1})
+	respondJSON(w, http.StatusOK, map[string]bool{"login": false})
+}
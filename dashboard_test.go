@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleFilesHidesDotPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"62201/mk.json", ".manifest.json", ".snapshot/62201/mk.json", "62201/.tmp"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := &Config{JSONFolder: dir, ExcelFolder: t.TempDir()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/files/{root}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		handleFiles(config, w, r)
+	})
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/files/json/", http.StatusOK},
+		{"/api/files/json/62201/mk.json", http.StatusOK},
+		{"/api/files/json/.manifest.json", http.StatusNotFound},
+		{"/api/files/json/.snapshot/", http.StatusNotFound},
+		{"/api/files/json/.snapshot/62201/mk.json", http.StatusNotFound},
+		{"/api/files/json/62201/.tmp", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/files/json/", nil))
+	var list []FileEntry
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "62201" {
+		t.Errorf("listing = %+v, want only 62201", list)
+	}
+}
+
+func TestToolAuthPrunesExpiredSessions(t *testing.T) {
+	a := &toolAuth{password: "rahasia", sessions: map[string]time.Time{
+		"lama": time.Now().Add(-time.Minute),
+		"baru": time.Now().Add(time.Hour),
+	}}
+	a.pruneSessions(time.Now())
+	if _, ok := a.sessions["lama"]; ok {
+		t.Error("expired session kept")
+	}
+	if _, ok := a.sessions["baru"]; !ok {
+		t.Error("valid session dropped")
+	}
+}
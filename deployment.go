@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultDeployment is the built-in profile of the SIAKAD version this
+// scraper was written against
+const DefaultDeployment = "default"
+
+// Deployment is the endpoint and form-field profile of one SIAKAD version.
+// Profiles in the config file start from the default one, so they only list
+// what their deployment does differently.
+type Deployment struct {
+	SessionCookie    string     `yaml:"session_cookie"`
+	LoginSuccess     string     `yaml:"login_success"`      // potongan body respons login yang berhasil
+	LoggedOutMarkers []string   `yaml:"logged_out_markers"` // potongan body media.php saat sesi habis
+	RekapMKBody      string     `yaml:"rekap_mk_body"`      // body POST daftar MK (paging, urutan)
+	RekapMHSBody     string     `yaml:"rekap_mhs_body"`     // body POST daftar mahasiswa
+	Endpoints        Endpoints  `yaml:"endpoints"`
+	Fields           FormFields `yaml:"form_fields"`
+}
+
+// Endpoints are the SIAKAD pages used to log in and to scrape
+type Endpoints struct {
+	Media     string `yaml:"media"`
+	Index     string `yaml:"index"`
+	Login     string `yaml:"login"`
+	Semesters string `yaml:"semesters"`
+	SetProdi  string `yaml:"set_prodi"`
+	RekapMK   string `yaml:"rekap_mk"`
+	ListNilai string `yaml:"list_nilai"`
+	Bobot     string `yaml:"bobot"`
+	RekapMHS  string `yaml:"rekap_mhs"`
+}
+
+// FormFields are the names of the form fields posted to the endpoints
+type FormFields struct {
+	Username       string `yaml:"username"`
+	Password       string `yaml:"password"`
+	Validation     string `yaml:"validation"`
+	HideValidation string `yaml:"hide_validation"`
+	HideIP         string `yaml:"hide_ip"`
+	Prodi          string `yaml:"prodi"`
+	ProgramKelas   string `yaml:"program_kelas"`
+	Semester       string `yaml:"semester"`
+	Param          string `yaml:"param"`
+	Cetak          string `yaml:"cetak"`
+	BobotFak       string `yaml:"bobot_fak"`
+	BobotJurusan   string `yaml:"bobot_jurusan"`
+	BobotProgram   string `yaml:"bobot_program"`
+	BobotKelas     string `yaml:"bobot_kelas"`
+	BobotKodeMK    string `yaml:"bobot_kode_mk"`
+}
+
+func defaultDeployment() Deployment {
+	return Deployment{
+		SessionCookie:    "PHPSESSID",
+		LoginSuccess:     `"success":true`,
+		LoggedOutMarkers: []string{"window.location = 'index.php'", "login", "Username"},
+		RekapMKBody:      "page=1&rows=300&sort=hari&order=asc",
+		RekapMHSBody:     "page=1&rows=500&",
+		Endpoints: Endpoints{
+			Media:     "/media.php",
+			Index:     "/index.php",
+			Login:     "/ceklogin.php?h=",
+			Semesters: "/_modul/aksi_umum.php?act=pilih_smtthnakd",
+			SetProdi:  "/_modul/mod_prodi_smthn/aksi_prodi_smthn.php",
+			RekapMK:   "/_modul/mod_nilmk/aksi_nilmk.php?act=rekapNILMK",
+			ListNilai: "/_modul/mod_nilmk/aksi_nilmk.php?act=listNILMK",
+			Bobot:     "/_modul/mod_nilmk/aksi_nilmk.php?act=loadBOBOT",
+			RekapMHS:  "/_modul/mod_datamhs/aksi_datamhs.php?act=list",
+		},
+		Fields: FormFields{
+			Username:       "username",
+			Password:       "password",
+			Validation:     "validation",
+			HideValidation: "hide_validation",
+			HideIP:         "hide_ipnya",
+			Prodi:          "ps",
+			ProgramKelas:   "pk",
+			Semester:       "smthn",
+			Param:          "param",
+			Cetak:          "cetak",
+			BobotFak:       "fak",
+			BobotJurusan:   "jrs",
+			BobotProgram:   "prg",
+			BobotKelas:     "kls",
+			BobotKodeMK:    "kmk",
+		},
+	}
+}
+
+// deploymentSetting is one setting of a profile with its key in the file
+type deploymentSetting struct {
+	key   string
+	value *string
+}
+
+// settings lists every string setting of the profile in file order
+func (d *Deployment) settings() []deploymentSetting {
+	e, f := &d.Endpoints, &d.Fields
+	return []deploymentSetting{
+		{"session_cookie", &d.SessionCookie},
+		{"login_success", &d.LoginSuccess},
+		{"rekap_mk_body", &d.RekapMKBody},
+		{"rekap_mhs_body", &d.RekapMHSBody},
+		{"endpoints.media", &e.Media},
+		{"endpoints.index", &e.Index},
+		{"endpoints.login", &e.Login},
+		{"endpoints.semesters", &e.Semesters},
+		{"endpoints.set_prodi", &e.SetProdi},
+		{"endpoints.rekap_mk", &e.RekapMK},
+		{"endpoints.list_nilai", &e.ListNilai},
+		{"endpoints.bobot", &e.Bobot},
+		{"endpoints.rekap_mhs", &e.RekapMHS},
+		{"form_fields.username", &f.Username},
+		{"form_fields.password", &f.Password},
+		{"form_fields.validation", &f.Validation},
+		{"form_fields.hide_validation", &f.HideValidation},
+		{"form_fields.hide_ip", &f.HideIP},
+		{"form_fields.prodi", &f.Prodi},
+		{"form_fields.program_kelas", &f.ProgramKelas},
+		{"form_fields.semester", &f.Semester},
+		{"form_fields.param", &f.Param},
+		{"form_fields.cetak", &f.Cetak},
+		{"form_fields.bobot_fak", &f.BobotFak},
+		{"form_fields.bobot_jurusan", &f.BobotJurusan},
+		{"form_fields.bobot_program", &f.BobotProgram},
+		{"form_fields.bobot_kelas", &f.BobotKelas},
+		{"form_fields.bobot_kode_mk", &f.BobotKodeMK},
+	}
+}
+
+// resolveDeployment returns the profile called name: a profile from the
+// config file filled up with the default, or the default itself
+func resolveDeployment(name string, profiles map[string]Deployment) (Deployment, error) {
+	def := defaultDeployment()
+	d, ok := profiles[name]
+	if !ok {
+		if name == DefaultDeployment {
+			return def, nil
+		}
+		names := []string{DefaultDeployment}
+		for n := range profiles {
+			if n != DefaultDeployment {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names[1:])
+		return Deployment{}, fmt.Errorf("siakad.deployment (SIAKAD_DEPLOYMENT) tidak dikenal: %s (tersedia: %s)", name, strings.Join(names, ", "))
+	}
+
+	defaults := def.settings()
+	for i, st := range d.settings() {
+		if strings.TrimSpace(*st.value) == "" {
+			*st.value = *defaults[i].value
+		}
+		if strings.HasPrefix(st.key, "endpoints.") && !strings.HasPrefix(*st.value, "/") {
+			return Deployment{}, fmt.Errorf("deployments.%s.%s harus diawali /: %s", name, st.key, *st.value)
+		}
+	}
+	if len(d.LoggedOutMarkers) == 0 {
+		d.LoggedOutMarkers = def.LoggedOutMarkers
+	}
+	for _, m := range d.LoggedOutMarkers {
+		// penanda kosong cocok dengan semua halaman, sesi selalu dianggap habis
+		if strings.TrimSpace(m) == "" {
+			return Deployment{}, fmt.Errorf("deployments.%s.logged_out_markers tidak boleh berisi penanda kosong", name)
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DosenClass is one class taught by a dosen in the semester
+type DosenClass struct {
+	KodeMK     string         `json:"kodemk"`
+	Namamk     string         `json:"namamk"`
+	Kelas      string         `json:"kelas"`
+	Cetak      bool           `json:"cetak"`
+	Jumlah     int            `json:"jumlah"`
+	Rata       float64        `json:"rata"`
+	Distribusi map[string]int `json:"distribusi,omitempty"`
+}
+
+// DosenReport aggregates the classes of one dosen
+type DosenReport struct {
+	Namadosen  string         `json:"namadosen"`
+	Kelas      []DosenClass   `json:"kelas"`
+	Mahasiswa  int            `json:"mahasiswa"`
+	Rata       float64        `json:"rata"`
+	Distribusi map[string]int `json:"distribusi"`
+	BelumCetak int            `json:"belum_cetak"`
+}
+
+func classKey(kodeMK, kelas string) string {
+	return kodeMK + "|" + kelas
+}
+
+// buildDosenReports groups every MK of the rekap by dosen; published classes
+// carry their statistics, unpublished ones are listed without figures.
+func buildDosenReports(all []MataKuliah, stats []ClassStats) []DosenReport {
+	byClass := make(map[string]ClassStats, len(stats))
+	for _, st := range stats {
+		byClass[classKey(st.KodeMK, st.Kelas)] = st
+	}
+
+	byDosen := map[string]*DosenReport{}
+	var order []string
+	for _, mk := range all {
+		nama := strings.TrimSpace(mk.Namadosen)
+		if nama == "" {
+			nama = "Tanpa Dosen"
+		}
+		rep, ok := byDosen[nama]
+		if !ok {
+			rep = &DosenReport{Namadosen: nama, Distribusi: map[string]int{}}
+			byDosen[nama] = rep
+			order = append(order, nama)
+		}
+
+		cls := DosenClass{KodeMK: mk.KodeMK, Namamk: mk.Namamk, Kelas: mk.Kelas, Cetak: mk.Cetak == CetakValue}
+		if !cls.Cetak {
+			rep.BelumCetak++
+		}
+		if st, ok := byClass[classKey(mk.KodeMK, mk.Kelas)]; ok {
+			cls.Jumlah, cls.Rata, cls.Distribusi = st.Jumlah, st.Rata, st.Distribusi
+			rep.Mahasiswa += st.Jumlah
+			rep.Rata += st.Rata * float64(st.Jumlah)
+			for h, c := range st.Distribusi {
+				rep.Distribusi[h] += c
+			}
+		}
+		rep.Kelas = append(rep.Kelas, cls)
+	}
+
+	sort.Strings(order)
+	reports := make([]DosenReport, 0, len(order))
+	for _, nama := range order {
+		rep := byDosen[nama]
+		if rep.Mahasiswa > 0 {
+			rep.Rata = round2(rep.Rata / float64(rep.Mahasiswa))
+		}
+		reports = append(reports, *rep)
+	}
+	return reports
+}
+
+func distribusiHeaders(scale GradeScale) []interface{} {
+	var h []interface{}
+	for _, g := range scale.Nilai {
+		h = append(h, g.Huruf)
+	}
+	return h
+}
+
+func distribusiValues(dist map[string]int, scale GradeScale) []interface{} {
+	var v []interface{}
+	for _, g := range scale.Nilai {
+		v = append(v, dist[g.Huruf])
+	}
+	return v
+}
+
+func cetakLabel(cetak bool) string {
+	if cetak {
+		return "Sudah"
+	}
+	return "Belum"
+}
+
+// writeDosenReports writes one workbook per dosen into a "Dosen" folder plus
+// the jurusan-wide "Rekap Dosen" overview. It returns the paths written.
+func writeDosenReports(cfg *Config, out mkOutput, reports []DosenReport, scale GradeScale) ([]string, error) {
+	folder := filepath.Join(out.folderExcel, "Dosen")
+	if err := os.MkdirAll(folder, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	ext := sheetExt(cfg.SheetFormat)
+	for _, rep := range reports {
+		headers := append([]interface{}{"Kode MK", "Nama MK", "Kelas", "Cetak", "Jumlah Mahasiswa", "Rata-rata"}, distribusiHeaders(scale)...)
+		rows := [][]interface{}{headers}
+		for _, k := range rep.Kelas {
+			row := []interface{}{k.KodeMK, k.Namamk, k.Kelas, cetakLabel(k.Cetak), k.Jumlah, k.Rata}
+			rows = append(rows, append(row, distribusiValues(k.Distribusi, scale)...))
+		}
+		path := filepath.Join(folder, sanitizeFilename(rep.Namadosen)+ext)
+		if err := writeTables(path, sheetTable{Name: "Kelas", Rows: rows}); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+
+	headers := append([]interface{}{"Dosen", "Jumlah Kelas", "Belum Cetak", "Jumlah Mahasiswa", "Rata-rata"}, distribusiHeaders(scale)...)
+	rows := [][]interface{}{headers}
+	for _, rep := range reports {
+		row := []interface{}{rep.Namadosen, len(rep.Kelas), rep.BelumCetak, rep.Mahasiswa, rep.Rata}
+		rows = append(rows, append(row, distribusiValues(rep.Distribusi, scale)...))
+	}
+	written, err := writeReport(cfg, out, "Rekap Dosen", reports, sheetTable{Name: "Rekap Dosen", Rows: rows})
+	return append(paths, written...), err
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// SMTPSPort is the SMTP submission port with implicit TLS (SMTPS)
+const SMTPSPort = 465
+
+// SMTPTimeout bounds dialing and the whole SMTP conversation of one email
+var SMTPTimeout = time.Minute
+
+// emailReport is the data of the run report templates
+type emailReport struct {
+	Manifest RunManifest
+	Durasi   string
+	Jurusan  []jurusanRunReport
+	Total    RunSummary
+}
+
+var emailFuncs = map[string]interface{}{
+	"waktu": func(t time.Time) string { return t.Format(time.DateTime) },
+}
+
+const emailText = `Laporan run {{.Manifest.ID}}
+Mode: {{.Manifest.Mode}}  Semester: {{.Manifest.Semester}}  Status: {{.Manifest.Status}}
+Mulai: {{waktu .Manifest.Start}}  Durasi: {{.Durasi}}
+{{- if .Manifest.Error}}
+Error: {{.Manifest.Error}}
+{{- end}}
+
+Total MK: {{.Total.OK}} tersimpan, {{.Total.Skipped}} skip, {{.Total.Failed}} gagal
+{{range .Jurusan}}
+== {{.Jurusan.NamaJrs}} ==
+MK: {{.Summary.OK}} tersimpan, {{.Summary.Skipped}} skip, {{.Summary.Failed}} gagal
+{{- range .Failures}}
+  gagal: {{.MataKuliah.KodeMK}} {{.MataKuliah.Namamk}} kelas {{.MataKuliah.Kelas}} ({{.MataKuliah.Namadosen}}): {{.Error}}
+{{- end}}
+{{- range .Anomalies}}
+  anomali: {{.Keterangan}}
+{{- end}}
+{{end}}`
+
+const emailHTML = `<!DOCTYPE html>
+<html><body style="font-family: sans-serif; font-size: 14px; color: #222">
+<h2 style="margin-bottom: 4px">Laporan run {{.Manifest.ID}}</h2>
+<p style="margin-top: 0">Mode <b>{{.Manifest.Mode}}</b>, semester <b>{{.Manifest.Semester}}</b>, status <b>{{.Manifest.Status}}</b><br>
+Mulai {{waktu .Manifest.Start}}, durasi {{.Durasi}}</p>
+{{- if .Manifest.Error}}
+<p style="color: #b00020">Error: {{.Manifest.Error}}</p>
+{{- end}}
+<table cellpadding="6" style="border-collapse: collapse; border: 1px solid #ccc">
+<tr style="background: #1f3a5f; color: #fff"><th align="left">Jurusan</th><th>Tersimpan</th><th>Skip</th><th>Gagal</th><th align="left">Anomali</th></tr>
+{{- range .Jurusan}}
+<tr style="border-top: 1px solid #ccc"><td>{{.Jurusan.NamaJrs}}</td><td align="center">{{.Summary.OK}}</td><td align="center">{{.Summary.Skipped}}</td>
+<td align="center"{{if .Summary.Failed}} style="color: #b00020; font-weight: bold"{{end}}>{{.Summary.Failed}}</td>
+<td>{{range .Anomalies}}{{.Keterangan}}<br>{{else}}-{{end}}</td></tr>
+{{- end}}
+<tr style="border-top: 2px solid #999; font-weight: bold"><td>Total</td><td align="center">{{.Total.OK}}</td><td align="center">{{.Total.Skipped}}</td><td align="center">{{.Total.Failed}}</td><td></td></tr>
+</table>
+{{- range .Jurusan}}{{if .Failures}}
+<h3>MK gagal - {{.Jurusan.NamaJrs}}</h3>
+<ul>
+{{- range .Failures}}
+<li>{{.MataKuliah.KodeMK}} {{.MataKuliah.Namamk}} kelas {{.MataKuliah.Kelas}} ({{.MataKuliah.Namadosen}}): {{.Error}}</li>
+{{- end}}
+</ul>
+{{- end}}{{end}}
+</body></html>
+`
+
+var (
+	emailTextTmpl = texttemplate.Must(texttemplate.New("text").Funcs(emailFuncs).Parse(emailText))
+	emailHTMLTmpl = htmltemplate.Must(htmltemplate.New("html").Funcs(emailFuncs).Parse(emailHTML))
+)
+
+// emailEnabled reports whether SMTP and at least one recipient are configured
+func emailEnabled(config *Config) bool {
+	return config.SMTPHost != "" && (len(config.EmailTo) > 0 || len(config.EmailToJurusan) > 0)
+}
+
+// sendRunEmails mails the run report: EMAIL_TO gets every jurusan, each
+// EMAIL_TO_<kodejrs> only the report of that jurusan. The consolidated
+// workbook holds the grades of every jurusan, so with EMAIL_ATTACH_WORKBOOK
+// it is attached to the EMAIL_TO message only.
+func sendRunEmails(config *Config, m RunManifest, workbook string) {
+	if !emailEnabled(config) {
+		return
+	}
+	var attachment string
+	if config.EmailAttachWorkbook {
+		attachment = workbook
+	}
+
+	if len(config.EmailTo) > 0 {
+		if err := sendRunEmail(config, config.EmailTo, m, nil, attachment); err != nil {
+			logf(LogWarn, "Gagal kirim email laporan ke %s: %v", strings.Join(config.EmailTo, ", "), err)
+		} else {
+			logf(LogInfo, "Email laporan terkirim ke %s", strings.Join(config.EmailTo, ", "))
+		}
+	}
+
+	kode := make([]string, 0, len(config.EmailToJurusan))
+	for k := range config.EmailToJurusan {
+		kode = append(kode, k)
+	}
+	sort.Strings(kode)
+	for _, k := range kode {
+		if !runHasJurusan(m, k) {
+			continue
+		}
+		to := config.EmailToJurusan[k]
+		if err := sendRunEmail(config, to, m, map[string]bool{k: true}, ""); err != nil {
+			logf(LogWarn, "Gagal kirim email laporan jurusan %s ke %s: %v", k, strings.Join(to, ", "), err)
+		} else {
+			logf(LogInfo, "Email laporan jurusan %s terkirim ke %s", k, strings.Join(to, ", "))
+		}
+	}
+}
+
+func runHasJurusan(m RunManifest, kodeJrs string) bool {
+	for _, j := range m.Jurusan {
+		if j.KodeJrs == kodeJrs {
+			return true
+		}
+	}
+	return false
+}
+
+func sendRunEmail(config *Config, to []string, m RunManifest, only map[string]bool, attachment string) error {
+	report := emailReport{Manifest: m, Durasi: formatDuration(m.End.Sub(m.Start)), Jurusan: buildJurusanRunReports(m, only)}
+	for _, j := range report.Jurusan {
+		report.Total.OK += j.Summary.OK
+		report.Total.Skipped += j.Summary.Skipped
+		report.Total.Failed += j.Summary.Failed
+	}
+
+	subject := fmt.Sprintf("[Scraper Nilai] %s semester %s: %d tersimpan, %d gagal", m.Mode, m.Semester, report.Total.OK, report.Total.Failed)
+	if len(report.Jurusan) == 1 {
+		subject = fmt.Sprintf("[Scraper Nilai] %s %s semester %s: %d tersimpan, %d gagal", report.Jurusan[0].Jurusan.NamaJrs, m.Mode, m.Semester, report.Total.OK, report.Total.Failed)
+	}
+
+	var text, html bytes.Buffer
+	if err := emailTextTmpl.Execute(&text, report); err != nil {
+		return err
+	}
+	if err := emailHTMLTmpl.Execute(&html, report); err != nil {
+		return err
+	}
+	msg, err := buildEmail(config.SMTPFrom, to, subject, text.Bytes(), html.Bytes(), attachment)
+	if err != nil {
+		return err
+	}
+
+	return sendMail(config, to, msg)
+}
+
+// sendMail delivers msg like smtp.SendMail, but bounded by SMTPTimeout so an
+// unreachable or stalled server cannot hold up the job worker. Port
+// SMTPSPort uses implicit TLS, other ports STARTTLS when the server offers it.
+func sendMail(config *Config, to []string, msg []byte) error {
+	addr := net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: config.SMTPHost}
+	dialer := &net.Dialer{Timeout: SMTPTimeout}
+	var conn net.Conn
+	var err error
+	if config.SMTPPort == SMTPSPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("gagal koneksi ke SMTP %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(SMTPTimeout))
+
+	c, err := smtp.NewClient(conn, config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if config.SMTPPort != SMTPSPort {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if config.SMTPUser != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("server SMTP %s tidak mendukung AUTH", addr)
+		}
+		if err := c.Auth(smtp.PlainAuth("", config.SMTPUser, config.SMTPPassword, config.SMTPHost)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(config.SMTPFrom); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildEmail renders a multipart/mixed message with a plain-text and HTML
+// alternative and an optional file attachment
+func buildEmail(from string, to []string, subject string, text, html []byte, attachment string) ([]byte, error) {
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := altWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(w, part.body)
+	}
+	altWriter.Close()
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + altWriter.Boundary()}})
+	if err != nil {
+		return nil, err
+	}
+	w.Write(alt.Bytes())
+
+	if attachment != "" {
+		data, err := os.ReadFile(attachment)
+		if err != nil {
+			return nil, fmt.Errorf("gagal baca lampiran %s: %w", attachment, err)
+		}
+		name := filepath.Base(attachment)
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(w, data)
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines
+func writeBase64(w interface{ Write([]byte) (int, error) }, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		w.Write([]byte(enc[:76] + "\r\n"))
+		enc = enc[76:]
+	}
+	w.Write([]byte(enc + "\r\n"))
+}
+
+// runEmail mails the report of a run manifest again, e.g. to test SMTP
+func runEmail(args []string) error {
+	fs := flag.NewFlagSet("email", flag.ExitOnError)
+	manifestPath := fs.String("manifest", "", "path manifest run (kosong = manifest terbaru di "+RunFolder+")")
+	fs.Parse(args)
+
+	config, err := loadOptions()
+	if err != nil {
+		return fmt.Errorf("gagal load konfigurasi: %w", err)
+	}
+	if !emailEnabled(config) {
+		return fmt.Errorf("SMTP_HOST dan EMAIL_TO/EMAIL_TO_<kodejrs> belum diisi")
+	}
+	path := *manifestPath
+	if path == "" {
+		if path, err = latestRunManifest(config.RunFolder); err != nil {
+			return err
+		}
+	}
+	run, err := loadRun(path)
+	if err != nil {
+		return err
+	}
+	workbook := strings.TrimSuffix(path, ".json") + sheetExt(config.SheetFormat)
+	if err := writeRunWorkbook(workbook, run.Manifest); err != nil {
+		return fmt.Errorf("gagal tulis workbook run: %w", err)
+	}
+	sendRunEmails(config, run.Manifest, workbook)
+	return nil
+}
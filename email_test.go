@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpMessage is one email accepted by fakeSMTP
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP is a minimal SMTP server without STARTTLS or AUTH that records
+// every message it accepts
+type fakeSMTP struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	var msg smtpMessage
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = smtpMessage{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 kirim")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// emailParts returns the decoded text part and the attachments of a message
+func emailParts(t *testing.T, data string) (string, map[string]string) {
+	t.Helper()
+	m, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	attachments := map[string]string{}
+	var walk func(r io.Reader, contentType string)
+	walk = func(r io.Reader, contentType string) {
+		_, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct := p.Header.Get("Content-Type")
+			if strings.HasPrefix(ct, "multipart/") {
+				walk(p, ct)
+				continue
+			}
+			body := decodeBase64Part(t, p)
+			if name := p.FileName(); name != "" {
+				attachments[name] = body
+			} else if strings.HasPrefix(ct, "text/plain") {
+				text = body
+			}
+		}
+	}
+	walk(m.Body, m.Header.Get("Content-Type"))
+	return text, attachments
+}
+
+func decodeBase64Part(t *testing.T, p *multipart.Part) string {
+	t.Helper()
+	raw, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestSendRunEmailsRoutesPerJurusan(t *testing.T) {
+	srv := newFakeSMTP(t)
+	workbook := filepath.Join(t.TempDir(), "rekap.xlsx")
+	if err := os.WriteFile(workbook, []byte("isi workbook"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	akuntansi := Jurusan{KodeJrs: "62201", NamaJrs: "Akuntansi"}
+	manajemen := Jurusan{KodeJrs: "61201", NamaJrs: "Manajemen"}
+	config := &Config{
+		SMTPHost: "127.0.0.1",
+		SMTPPort: srv.port(),
+		SMTPFrom: "scraper@kampus.ac.id",
+		EmailTo:  []string{"dekan@kampus.ac.id"},
+		EmailToJurusan: map[string][]string{
+			"62201": {"kaprodi.akuntansi@kampus.ac.id"},
+			"99999": {"tidak.ikut@kampus.ac.id"},
+		},
+		EmailAttachWorkbook: true,
+	}
+	m := RunManifest{
+		ID:       "run-1",
+		Mode:     "all",
+		Semester: "20241",
+		Jurusan:  []Jurusan{akuntansi, manajemen},
+		Start:    time.Now().Add(-time.Minute),
+		End:      time.Now(),
+		MK: []MKStatus{
+			{Jurusan: akuntansi, Status: StatusOK},
+			{Jurusan: manajemen, Status: StatusOK},
+			{Jurusan: manajemen, Status: StatusFailed, Error: "timeout", MataKuliah: MataKuliah{KodeMK: "MN101"}},
+		},
+	}
+
+	sendRunEmails(config, m, workbook)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.messages) != 2 {
+		t.Fatalf("got %d emails, want 2 (EMAIL_TO and EMAIL_TO_62201)", len(srv.messages))
+	}
+	for _, msg := range srv.messages {
+		if msg.from != config.SMTPFrom {
+			t.Errorf("MAIL FROM = %s", msg.from)
+		}
+		text, attachments := emailParts(t, msg.data)
+		switch strings.Join(msg.to, ",") {
+		case "dekan@kampus.ac.id":
+			if !strings.Contains(text, "Akuntansi") || !strings.Contains(text, "Manajemen") || !strings.Contains(text, "MN101") {
+				t.Errorf("full report is missing a jurusan:\n%s", text)
+			}
+			if attachments["rekap.xlsx"] != "isi workbook" {
+				t.Errorf("full report: attachment = %q", attachments)
+			}
+		case "kaprodi.akuntansi@kampus.ac.id":
+			if !strings.Contains(text, "Akuntansi") || strings.Contains(text, "Manajemen") {
+				t.Errorf("jurusan report is not limited to 62201:\n%s", text)
+			}
+			// workbook berisi nilai semua jurusan
+			if len(attachments) != 0 {
+				t.Errorf("jurusan report carries attachments %q", attachments)
+			}
+		default:
+			t.Errorf("unexpected recipients %v", msg.to)
+		}
+	}
+}
+
+func TestSendMailTimesOut(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// terima koneksi tapi tidak pernah menyapa
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	old := SMTPTimeout
+	SMTPTimeout = 200 * time.Millisecond
+	defer func() { SMTPTimeout = old }()
+
+	config := &Config{SMTPHost: "127.0.0.1", SMTPPort: ln.Addr().(*net.TCPAddr).Port, SMTPFrom: "a@b"}
+	start := time.Now()
+	if err := sendMail(config, []string{"c@d"}, []byte("Subject: x\r\n\r\nx")); err == nil {
+		t.Fatal("expected an error from a silent server")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("sendMail did not honor SMTPTimeout")
+	}
+}
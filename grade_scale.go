@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GradeEntry maps a minimum nil_angka to a letter grade and its quality point (mutu)
+type GradeEntry struct {
+	Huruf string  `json:"huruf"`
+	Min   float64 `json:"min"`
+	Mutu  float64 `json:"mutu"`
+}
+
+// GradeScale is a named letter-grade scale valid from semester Mulai onward
+type GradeScale struct {
+	Nama  string       `json:"nama"`
+	Mulai string       `json:"mulai"` // smtthnakd, kosong = berlaku untuk semua semester
+	Nilai []GradeEntry `json:"nilai"`
+}
+
+// GradeScales is the content of GradeScaleFile
+type GradeScales struct {
+	Skala []GradeScale `json:"skala"`
+}
+
+// defaultGradeScale is used when GradeScaleFile is missing or no scale applies
+var defaultGradeScale = GradeScale{
+	Nama: "Default",
+	Nilai: []GradeEntry{
+		{"A", 85, 4.0},
+		{"A-", 80, 3.7},
+		{"B+", 75, 3.3},
+		{"B", 70, 3.0},
+		{"B-", 65, 2.7},
+		{"C+", 60, 2.3},
+		{"C", 55, 2.0},
+		{"D", 45, 1.0},
+		{"E", 0, 0},
+	},
+}
+
+// loadGradeScales reads the grade scale file; a missing file yields the default scale only
+func loadGradeScales(path string) (*GradeScales, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &GradeScales{}, nil
+		}
+		return nil, fmt.Errorf("gagal baca %s: %w", path, err)
+	}
+	var gs GradeScales
+	if err := json.Unmarshal(data, &gs); err != nil {
+		return nil, fmt.Errorf("gagal parsing %s: %w", path, err)
+	}
+	for i := range gs.Skala {
+		sc := &gs.Skala[i]
+		if len(sc.Nilai) == 0 {
+			return nil, fmt.Errorf("skala %q di %s tidak punya nilai", sc.Nama, path)
+		}
+		sort.Slice(sc.Nilai, func(a, b int) bool { return sc.Nilai[a].Min > sc.Nilai[b].Min })
+	}
+	return &gs, nil
+}
+
+// For returns the scale with the latest Mulai that is not after semester
+func (gs *GradeScales) For(semester string) GradeScale {
+	best := -1
+	for i, sc := range gs.Skala {
+		if sc.Mulai > semester {
+			continue
+		}
+		if best < 0 || sc.Mulai >= gs.Skala[best].Mulai {
+			best = i
+		}
+	}
+	if best < 0 {
+		return defaultGradeScale
+	}
+	return gs.Skala[best]
+}
+
+// Huruf returns the letter grade for angka under the scale
+func (sc GradeScale) Huruf(angka float64) string {
+	for _, g := range sc.Nilai {
+		if angka >= g.Min {
+			return g.Huruf
+		}
+	}
+	return ""
+}
+
+// Mutu returns the quality point of a letter grade; ok is false for unknown letters
+func (sc GradeScale) Mutu(huruf string) (float64, bool) {
+	huruf = strings.ToUpper(strings.TrimSpace(huruf))
+	for _, g := range sc.Nilai {
+		if strings.EqualFold(g.Huruf, huruf) {
+			return g.Mutu, true
+		}
+	}
+	return 0, false
+}
+
+// HurufMismatch is a row whose nil_huruf disagrees with nil_angka under the scale
+type HurufMismatch struct {
+	NIM        string `json:"nim"`
+	Nama       string `json:"nama"`
+	KodeMK     string `json:"kodemk"`
+	Namamk     string `json:"namamk"`
+	Kelas      string `json:"kelas"`
+	Namadosen  string `json:"namadosen"`
+	NilAngka   string `json:"nil_angka"`
+	NilHuruf   string `json:"nil_huruf"`
+	Seharusnya string `json:"seharusnya"`
+}
+
+// HurufSummary counts the validation result of one jurusan-semester
+type HurufSummary struct {
+	Jurusan     string `json:"jurusan"`
+	Semester    string `json:"semester"`
+	Skala       string `json:"skala"`
+	Total       int    `json:"total"`
+	Sesuai      int    `json:"sesuai"`
+	TidakSesuai int    `json:"tidak_sesuai"`
+	Kosong      int    `json:"kosong"`
+}
+
+// HurufReport is the JSON layout of the huruf validation report
+type HurufReport struct {
+	Ringkasan HurufSummary    `json:"ringkasan"`
+	Detail    []HurufMismatch `json:"detail"`
+}
+
+// validateHuruf checks every nil_huruf against nil_angka using scale
+func validateHuruf(results []mkResult, scale GradeScale) ([]HurufMismatch, HurufSummary) {
+	var detail []HurufMismatch
+	sum := HurufSummary{Skala: scale.Nama}
+	for _, res := range results {
+		mk := res.MataKuliah
+		for _, n := range res.Nilai {
+			sum.Total++
+			angka, ok := parseScore(n.NilAngka)
+			if !ok || strings.TrimSpace(n.NilHuruf) == "" {
+				sum.Kosong++
+				continue
+			}
+			expected := scale.Huruf(angka)
+			if strings.EqualFold(strings.TrimSpace(n.NilHuruf), expected) {
+				sum.Sesuai++
+				continue
+			}
+			sum.TidakSesuai++
+			detail = append(detail, HurufMismatch{
+				NIM:        n.NIM,
+				Nama:       n.Nama,
+				KodeMK:     mk.KodeMK,
+				Namamk:     mk.Namamk,
+				Kelas:      mk.Kelas,
+				Namadosen:  mk.Namadosen,
+				NilAngka:   n.NilAngka,
+				NilHuruf:   n.NilHuruf,
+				Seharusnya: expected,
+			})
+		}
+	}
+	return detail, sum
+}
+
+// writeHurufCheck writes the validation result as "Validasi Huruf" JSON and spreadsheet
+func writeHurufCheck(cfg *Config, out mkOutput, detail []HurufMismatch, sum HurufSummary) ([]string, error) {
+	ringkasan := [][]interface{}{
+		{"Jurusan", "Semester", "Skala", "Total Nilai", "Sesuai", "Tidak Sesuai", "Kosong"},
+		{sum.Jurusan, sum.Semester, sum.Skala, sum.Total, sum.Sesuai, sum.TidakSesuai, sum.Kosong},
+	}
+	rows := [][]interface{}{{"NIM", "Nama", "Kode MK", "Nama MK", "Kelas", "Dosen", "Nilai Angka", "Nilai Huruf", "Seharusnya"}}
+	for _, d := range detail {
+		rows = append(rows, []interface{}{d.NIM, d.Nama, d.KodeMK, d.Namamk, d.Kelas, d.Namadosen, d.NilAngka, d.NilHuruf, d.Seharusnya})
+	}
+	return writeReport(cfg, out, "Validasi Huruf", HurufReport{Ringkasan: sum, Detail: detail},
+		sheetTable{Name: "Ringkasan", Rows: ringkasan},
+		sheetTable{Name: "Detail", Rows: rows},
+	)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SemesterIPK is the standing of a student at the end of one semester
+type SemesterIPK struct {
+	Semester     string  `json:"semester"`
+	SKS          int     `json:"sks"`
+	IPS          float64 `json:"ips"`
+	SKSKumulatif int     `json:"sks_kumulatif"`
+	IPK          float64 `json:"ipk"`
+	Turun        bool    `json:"turun"`
+}
+
+// RiwayatMK is one attempt of a mata kuliah in the student's history
+type RiwayatMK struct {
+	Semester string  `json:"semester"`
+	KodeMK   string  `json:"kodemk"`
+	Namamk   string  `json:"namamk"`
+	SKS      int     `json:"sks"`
+	NilHuruf string  `json:"nil_huruf"`
+	Mutu     float64 `json:"mutu"`
+	Ulang    bool    `json:"ulang"`   // MK diambil lebih dari sekali
+	Dipakai  bool    `json:"dipakai"` // nilai terbaik yang masuk IPK
+}
+
+// RiwayatIPK is the cross-semester history of one student
+type RiwayatIPK struct {
+	NIM        string        `json:"nim"`
+	Nama       string        `json:"nama"`
+	Semester   []SemesterIPK `json:"semester"`
+	MK         []RiwayatMK   `json:"mk"`
+	IPK        float64       `json:"ipk"`
+	Peringatan bool          `json:"peringatan"`
+}
+
+// runIPK combines the KHS of every scraped semester of a jurusan into a per-student IPK history
+func runIPK(args []string) error {
+	fs := flag.NewFlagSet("ipk", flag.ExitOnError)
+	kode := fs.String("jurusan", "", "kodejrs atau nama jurusan (kosong = pilih dari daftar)")
+	drop := fs.Float64("turun", 0.75, "penurunan IPS dari semester sebelumnya yang ditandai")
+	fs.Parse(args)
+
+	config, err := loadOptions()
+	if err != nil {
+		return err
+	}
+
+	var jur Jurusan
+	if *kode != "" {
+		jur, err = findJurusan(config, *kode)
+	} else {
+		jur, err = loadJurusan(config)
+	}
+	if err != nil {
+		return err
+	}
+
+	khs, err := readKHSHistory(filepath.Join(config.JSONFolder, jur.NamaJrs))
+	if err != nil {
+		return err
+	}
+	if len(khs) == 0 {
+		return fmt.Errorf("belum ada KHS untuk jurusan %s, jalankan scraping nilai dulu", jur.NamaJrs)
+	}
+
+	riwayat := buildRiwayatIPK(khs, *drop)
+	out := mkOutput{
+		folderJSON:  filepath.Join(config.JSONFolder, jur.NamaJrs),
+		folderExcel: filepath.Join(config.ExcelFolder, jur.NamaJrs),
+	}
+	if err := os.MkdirAll(out.folderExcel, os.ModePerm); err != nil {
+		return err
+	}
+	paths, err := writeRiwayatIPK(config, out, riwayat)
+	if err != nil {
+		return fmt.Errorf("gagal tulis riwayat IPK: %w", err)
+	}
+
+	peringatan := 0
+	for _, r := range riwayat {
+		if r.Peringatan {
+			peringatan++
+		}
+	}
+	logf(LogInfo, "Jurusan %s: riwayat IPK %d mahasiswa dari %d semester, %d ditandai turun", jur.NamaJrs, len(riwayat), len(khs), peringatan)
+	for _, p := range paths {
+		logf(LogInfo, "Berhasil simpan riwayat IPK ke: %s", p)
+	}
+	return nil
+}
+
+// readKHSHistory reads KHS.json of every semester folder under dir, keyed by semester
+func readKHSHistory(dir string) (map[string][]KHS, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "KHS.json"))
+	if err != nil {
+		return nil, err
+	}
+	history := map[string][]KHS{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("gagal baca %s: %w", f, err)
+		}
+		var list []KHS
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("gagal parsing %s: %w", f, err)
+		}
+		history[filepath.Base(filepath.Dir(f))] = list
+	}
+	return history, nil
+}
+
+// buildRiwayatIPK computes the cumulative IPK per semester. A repeated MK
+// only counts with its best grade. Students whose IPS falls by at least
+// drop compared to the previous semester are flagged.
+func buildRiwayatIPK(history map[string][]KHS, drop float64) []RiwayatIPK {
+	semesters := make([]string, 0, len(history))
+	for sm := range history {
+		semesters = append(semesters, sm)
+	}
+	sort.Strings(semesters)
+
+	byNIM := map[string]*RiwayatIPK{}
+	for _, sm := range semesters {
+		for _, k := range history[sm] {
+			r, ok := byNIM[k.NIM]
+			if !ok {
+				r = &RiwayatIPK{NIM: k.NIM, Nama: k.Nama}
+				byNIM[k.NIM] = r
+			}
+			for _, it := range k.MK {
+				r.MK = append(r.MK, RiwayatMK{Semester: sm, KodeMK: it.KodeMK, Namamk: it.Namamk, SKS: it.SKS, NilHuruf: it.NilHuruf, Mutu: it.Mutu, Dipakai: it.Dihitung})
+			}
+			r.Semester = append(r.Semester, SemesterIPK{Semester: sm, SKS: k.TotalSKS, IPS: k.IPS})
+		}
+	}
+
+	list := make([]RiwayatIPK, 0, len(byNIM))
+	for _, r := range byNIM {
+		for i := range r.Semester {
+			sks, ipk := ipkUntil(r.MK, r.Semester[i].Semester)
+			r.Semester[i].SKSKumulatif, r.Semester[i].IPK = sks, ipk
+			if i > 0 && r.Semester[i-1].IPS-r.Semester[i].IPS >= drop {
+				r.Semester[i].Turun = true
+				r.Peringatan = true
+			}
+		}
+		markBestAttempts(r.MK)
+		if n := len(r.Semester); n > 0 {
+			r.IPK = r.Semester[n-1].IPK
+		}
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NIM < list[j].NIM })
+	return list
+}
+
+// bestAttempts returns, per KodeMK, the index of the best counted attempt up to
+// semester until (empty = all semesters)
+func bestAttempts(mk []RiwayatMK, until string) map[string]int {
+	best := map[string]int{}
+	for i, m := range mk {
+		if (until != "" && m.Semester > until) || !m.Dipakai {
+			continue
+		}
+		if j, ok := best[m.KodeMK]; !ok || m.Mutu > mk[j].Mutu {
+			best[m.KodeMK] = i
+		}
+	}
+	return best
+}
+
+// ipkUntil returns the cumulative SKS and IPK using the best attempt of each MK up to semester
+func ipkUntil(mk []RiwayatMK, until string) (int, float64) {
+	var sks int
+	var bobot float64
+	for _, i := range bestAttempts(mk, until) {
+		sks += mk[i].SKS
+		bobot += float64(mk[i].SKS) * mk[i].Mutu
+	}
+	if sks == 0 {
+		return 0, 0
+	}
+	return sks, round2(bobot / float64(sks))
+}
+
+// markBestAttempts flags repeated MK and keeps Dipakai only on the best attempt
+func markBestAttempts(mk []RiwayatMK) {
+	count := map[string]int{}
+	for _, m := range mk {
+		count[m.KodeMK]++
+	}
+	best := bestAttempts(mk, "")
+	for i := range mk {
+		mk[i].Ulang = count[mk[i].KodeMK] > 1
+		mk[i].Dipakai = best[mk[i].KodeMK] == i && mk[i].Dipakai
+	}
+}
+
+// writeRiwayatIPK writes the history as "Riwayat IPK" JSON and spreadsheet
+func writeRiwayatIPK(cfg *Config, out mkOutput, list []RiwayatIPK) ([]string, error) {
+	ringkasan := [][]interface{}{{"NIM", "Nama", "Semester", "SKS", "IPS", "SKS Kumulatif", "IPK", "Turun"}}
+	mk := [][]interface{}{{"NIM", "Nama", "Semester", "Kode MK", "Nama MK", "SKS", "Nilai Huruf", "Mutu", "Ulang", "Dipakai"}}
+	for _, r := range list {
+		for _, s := range r.Semester {
+			ringkasan = append(ringkasan, []interface{}{r.NIM, r.Nama, s.Semester, s.SKS, s.IPS, s.SKSKumulatif, s.IPK, s.Turun})
+		}
+		for _, m := range r.MK {
+			mk = append(mk, []interface{}{r.NIM, r.Nama, m.Semester, m.KodeMK, m.Namamk, m.SKS, m.NilHuruf, m.Mutu, m.Ulang, m.Dipakai})
+		}
+	}
+	return writeReport(cfg, out, "Riwayat IPK", list,
+		sheetTable{Name: "IPK", Rows: ringkasan},
+		sheetTable{Name: "Mata Kuliah", Rows: mk},
+	)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// SemesterCurrent in a ScrapeSpec means the newest semester SIAKAD offers
+const SemesterCurrent = "current"
+
+// ScrapeSpec describes a non-interactive scrape
+type ScrapeSpec struct {
+	Mode     string   `json:"mode"`            // nilai, mahasiswa atau keduanya
+	Semester string   `json:"semester"`        // smtthnakd (mis. 20241) atau "current"
+	Jurusan  []string `json:"jurusan"`         // kodejrs atau nama; kosong = semua di jurusan.json
+	Tahun    string   `json:"tahun,omitempty"` // filter tahun masuk mahasiswa
+}
+
+// resolve validates the spec and looks up its jurusan in config.JurusanFile
+func (spec ScrapeSpec) resolve(config *Config) ([]Jurusan, error) {
+	switch spec.Mode {
+	case ModeNilai, ModeMahasiswa, ModeKeduanya:
+	default:
+		return nil, fmt.Errorf("mode tidak valid: %q (pilih %s, %s atau %s)", spec.Mode, ModeNilai, ModeMahasiswa, ModeKeduanya)
+	}
+	if spec.Semester == "" {
+		return nil, fmt.Errorf("semester wajib diisi")
+	}
+	if spec.Tahun != "" {
+		if _, err := strconv.Atoi(spec.Tahun); err != nil {
+			return nil, fmt.Errorf("tahun tidak valid: %s", spec.Tahun)
+		}
+	}
+	if len(spec.Jurusan) == 0 {
+		return readJurusanList(config)
+	}
+	jurusan := make([]Jurusan, 0, len(spec.Jurusan))
+	for _, key := range spec.Jurusan {
+		jur, err := findJurusan(config, key)
+		if err != nil {
+			return nil, err
+		}
+		jurusan = append(jurusan, jur)
+	}
+	return jurusan, nil
+}
+
+// latestSemester returns the semester with the highest smtthnakd; SIAKAD does
+// not list them in a fixed order
+func latestSemester(semesters []Semester) (Semester, bool) {
+	if len(semesters) == 0 {
+		return Semester{}, false
+	}
+	latest := semesters[0]
+	for _, s := range semesters[1:] {
+		if len(s.Smtthnakd) > len(latest.Smtthnakd) || (len(s.Smtthnakd) == len(latest.Smtthnakd) && s.Smtthnakd > latest.Smtthnakd) {
+			latest = s
+		}
+	}
+	return latest, true
+}
+
+// runScrape scrapes every jurusan of the spec into run. A failing jurusan
+// does not stop the others; all failures are returned together.
+func runScrape(scraper *Scraper, spec ScrapeSpec, jurusan []Jurusan, run *Run) error {
+	run.SetMode(spec.Mode)
+	if spec.Semester == SemesterCurrent {
+		semesters, err := scraper.ListSemesters()
+		if err != nil {
+			return fmt.Errorf("gagal ambil semester terbaru: %w", err)
+		}
+		latest, ok := latestSemester(semesters)
+		if !ok {
+			return fmt.Errorf("SIAKAD tidak mengembalikan semester")
+		}
+		spec.Semester = latest.Smtthnakd
+		logf(LogInfo, "Semester terbaru: %s (%s)", spec.Semester, latest.Keterangan)
+	}
+	run.SetSemester(spec.Semester)
+	terminal.Plan(len(jurusan))
+	defer terminal.Plan(0)
+
+	var errs []error
+	for _, jur := range jurusan {
+		run.AddJurusan(jur)
+		if spec.Mode == ModeNilai || spec.Mode == ModeKeduanya {
+			if err := processJurusan(scraper, jur, spec.Semester, run); err != nil {
+				logf(LogError, "Gagal proses jurusan %s: %v", jur.NamaJrs, err)
+				errs = append(errs, fmt.Errorf("gagal proses jurusan %s: %w", jur.NamaJrs, err))
+				continue
+			}
+		}
+		if spec.Mode == ModeMahasiswa || spec.Mode == ModeKeduanya {
+			if err := processMHS(scraper, jur, spec.Semester, spec.Tahun, run); err != nil {
+				logf(LogError, "Gagal proses mahasiswa %s: %v", jur.NamaJrs, err)
+				errs = append(errs, fmt.Errorf("gagal proses mahasiswa %s: %w", jur.NamaJrs, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Job is one scrape submitted to the JobManager
+type Job struct {
+	ID       string     `json:"id"`
+	Schedule string     `json:"schedule,omitempty"` // nama jadwal daemon, kosong = dari API
+	Spec     ScrapeSpec `json:"spec"`
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	RunID    string     `json:"run_id,omitempty"`
+	Summary  RunSummary `json:"summary"`
+	Progress []BarState `json:"progress,omitempty"`
+	Files    []string   `json:"files,omitempty"`
+
+	jurusan []Jurusan
+	run     *Run
+}
+
+// JobManager runs submitted jobs one at a time on a single SIAKAD session,
+// since SetProdi state belongs to the session
+type JobManager struct {
+	mu      sync.Mutex
+	session sync.Mutex // dipegang selama autentikasi dan selama job berjalan
+	scraper *Scraper
+	jobs    map[string]*Job
+	order   []string
+	next    int
+	queue   chan *Job
+	closed  bool
+	stopped chan struct{}
+}
+
+func newJobManager(scraper *Scraper) *JobManager {
+	m := &JobManager{scraper: scraper, jobs: map[string]*Job{}, queue: make(chan *Job, 100), stopped: make(chan struct{})}
+	go m.work()
+	return m
+}
+
+// Submit validates spec and queues it; schedule names the daemon schedule
+// that submitted it, if any
+func (m *JobManager) Submit(spec ScrapeSpec, schedule string) (Job, error) {
+	jurusan, err := spec.resolve(m.scraper.config)
+	if err != nil {
+		return Job{}, err
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("job manager sudah berhenti")
+	}
+	m.next++
+	job := &Job{
+		ID:       strconv.Itoa(m.next),
+		Schedule: schedule,
+		Spec:     spec,
+		Status:   JobQueued,
+		Created:  time.Now(),
+		jurusan:  jurusan,
+	}
+	select {
+	case m.queue <- job:
+	default:
+		m.next--
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("antrian job penuh")
+	}
+	m.jobs[job.ID] = job
+	m.order = append(m.order, job.ID)
+	m.mu.Unlock()
+	logf(LogInfo, "Job %s diterima: %s semester %s", job.ID, spec.Mode, spec.Semester)
+	return m.snapshot(job), nil
+}
+
+// Get returns a copy of the job with its current progress
+func (m *JobManager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, false
+	}
+	return m.snapshot(job), true
+}
+
+// List returns every job, oldest first
+func (m *JobManager) List() []Job {
+	m.mu.Lock()
+	jobs := make([]*Job, len(m.order))
+	for i, id := range m.order {
+		jobs[i] = m.jobs[id]
+	}
+	m.mu.Unlock()
+
+	list := make([]Job, len(jobs))
+	for i, job := range jobs {
+		list[i] = m.snapshot(job)
+	}
+	return list
+}
+
+// Semesters lists the semesters on the shared session, waiting for a running
+// job. The session is only re-authenticated when the listing fails.
+func (m *JobManager) Semesters() ([]Semester, error) {
+	m.session.Lock()
+	defer m.session.Unlock()
+	if semesters, err := m.scraper.ListSemesters(); err == nil {
+		return semesters, nil
+	}
+	if err := handleAuthentication(m.scraper); err != nil {
+		return nil, fmt.Errorf("gagal autentikasi: %w", err)
+	}
+	return m.scraper.ListSemesters()
+}
+
+// KeepAlive re-checks the session every interval so it does not expire
+// between scheduled jobs. A running job already keeps the session busy.
+func (m *JobManager) KeepAlive(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !m.session.TryLock() {
+			continue
+		}
+		err := handleAuthentication(m.scraper)
+		m.session.Unlock()
+		if err != nil {
+			logf(LogWarn, "Gagal menjaga sesi SIAKAD: %v", err)
+		}
+	}
+}
+
+func (m *JobManager) authenticate() error {
+	m.session.Lock()
+	defer m.session.Unlock()
+	if err := handleAuthentication(m.scraper); err != nil {
+		return fmt.Errorf("gagal autentikasi: %w", err)
+	}
+	return nil
+}
+
+func (m *JobManager) snapshot(job *Job) Job {
+	m.mu.Lock()
+	cp := *job
+	run := job.run
+	m.mu.Unlock()
+
+	if cp.Status == JobRunning {
+		cp.Progress = terminal.Snapshot()
+	}
+	if run != nil {
+		cp.Summary = run.Summary()
+		cp.Files = run.OutputFiles()
+	}
+	return cp
+}
+
+// Shutdown waits for the running job to finish; queued jobs are cancelled
+func (m *JobManager) Shutdown() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+	<-m.stopped
+}
+
+func (m *JobManager) work() {
+	defer close(m.stopped)
+	for job := range m.queue {
+		m.mu.Lock()
+		closed := m.closed
+		if closed {
+			now := time.Now()
+			job.Status, job.Error, job.Finished = JobFailed, "dibatalkan", &now
+		}
+		m.mu.Unlock()
+		if !closed {
+			m.runJob(job)
+		}
+	}
+}
+
+func (m *JobManager) runJob(job *Job) {
+	run := newRun(m.scraper.config)
+	now := time.Now()
+	m.mu.Lock()
+	job.Status, job.Started, job.run, job.RunID = JobRunning, &now, run, run.Manifest.ID
+	m.mu.Unlock()
+	logf(LogInfo, "Job %s mulai (run %s)", job.ID, run.Manifest.ID)
+
+	err := m.authenticate()
+	if err == nil {
+		m.session.Lock()
+		err = runScrape(m.scraper, job.Spec, job.jurusan, run)
+		m.session.Unlock()
+	}
+	finishRun(m.scraper.config, run, err)
+
+	end := time.Now()
+	m.mu.Lock()
+	job.Finished = &end
+	job.Status = JobDone
+	if err != nil {
+		job.Status, job.Error = JobFailed, err.Error()
+	}
+	m.mu.Unlock()
+	logf(LogInfo, "Job %s selesai: %s", job.ID, job.Status)
+}
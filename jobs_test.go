@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestLatestSemester(t *testing.T) {
+	tests := []struct {
+		name      string
+		semesters []string
+		want      string
+	}{
+		{"newest first", []string{"20242", "20241", "20232"}, "20242"},
+		{"oldest first", []string{"20231", "20232", "20241"}, "20241"},
+		{"unordered", []string{"20232", "20251", "20241"}, "20251"},
+		{"single", []string{"20241"}, "20241"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var semesters []Semester
+			for _, s := range tt.semesters {
+				semesters = append(semesters, Semester{Smtthnakd: s})
+			}
+			got, ok := latestSemester(semesters)
+			if !ok || got.Smtthnakd != tt.want {
+				t.Errorf("latestSemester(%v) = %q, %v; want %q", tt.semesters, got.Smtthnakd, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := latestSemester(nil); ok {
+		t.Error("latestSemester(nil) reported a semester")
+	}
+}
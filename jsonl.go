@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// JSONLSchemaVersion is bumped whenever the envelope layout changes
+const JSONLSchemaVersion = 2
+
+// Record kinds inside a JSONL file
+const (
+	KindNilai = "nilai"
+	KindBobot = "bobot"
+)
+
+// Envelope wraps a single scraped record with the context it came from
+type Envelope struct {
+	Schema    int         `json:"schema"`
+	Kind      string      `json:"kind"`
+	ScrapedAt time.Time   `json:"scraped_at"`
+	Host      string      `json:"host"`
+	Semester  string      `json:"semester"`
+	KodeJrs   string      `json:"kodejrs"`
+	KodeMK    string      `json:"kodemk"`
+	Kelas     string      `json:"kelas"`
+	Published bool        `json:"published"`
+	Data      interface{} `json:"data"`
+}
+
+// jsonlWriter appends envelopes to a file, one JSON object per line.
+// Safe for use from multiple goroutines.
+type jsonlWriter struct {
+	mu       sync.Mutex
+	file     *os.File
+	enc      *json.Encoder
+	host     string
+	semester string
+}
+
+func newJSONLWriter(path, baseURL, semester string) (*jsonlWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("gagal buka %s: %w", path, err)
+	}
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return &jsonlWriter{file: file, enc: json.NewEncoder(meteredWriter{file}), host: host, semester: semester}, nil
+}
+
+// WriteMK writes every record of a mata kuliah as its own envelope line
+func (w *jsonlWriter) WriteMK(kind string, mk MataKuliah, records ...interface{}) error {
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, rec := range records {
+		env := Envelope{
+			Schema:    JSONLSchemaVersion,
+			Kind:      kind,
+			ScrapedAt: now,
+			Host:      w.host,
+			Semester:  w.semester,
+			KodeJrs:   mk.KodeJrs,
+			KodeMK:    mk.KodeMK,
+			Kelas:     mk.Kelas,
+			Published: mk.Cetak == CetakValue,
+			Data:      rec,
+		}
+		if err := w.enc.Encode(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path returns the file the writer appends to
+func (w *jsonlWriter) Path() string {
+	return w.file.Name()
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.file.Close()
+}
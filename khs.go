@@ -0,0 +1,97 @@
+package main
+
+import "sort"
+
+// KHSItem is one mata kuliah on a student's semester result
+type KHSItem struct {
+	KodeMK   string  `json:"kodemk"`
+	Namamk   string  `json:"namamk"`
+	Kelas    string  `json:"kelas"`
+	SKS      int     `json:"sks"`
+	NilAngka string  `json:"nil_angka"`
+	NilHuruf string  `json:"nil_huruf"`
+	Mutu     float64 `json:"mutu"`
+	Dihitung bool    `json:"dihitung"` // ikut dihitung dalam IPS
+}
+
+// KHS is the semester result (Kartu Hasil Studi) of one student
+type KHS struct {
+	NIM      string    `json:"nim"`
+	Nama     string    `json:"nama"`
+	Semester string    `json:"semester"`
+	MK       []KHSItem `json:"mk"`
+	TotalSKS int       `json:"total_sks"`
+	IPS      float64   `json:"ips"`
+}
+
+// buildKHS pivots the nilai of every class by NIM and computes IPS.
+// MK whose huruf is not on the scale are listed but left out of IPS.
+func buildKHS(results []mkResult, scale GradeScale, semester string) []KHS {
+	byNIM := map[string]*KHS{}
+	for _, res := range results {
+		mk := res.MataKuliah
+		sks := 0
+		if v, ok := parseScore(mk.SKS); ok {
+			sks = int(v)
+		}
+		for _, n := range res.Nilai {
+			k, ok := byNIM[n.NIM]
+			if !ok {
+				k = &KHS{NIM: n.NIM, Nama: n.Nama, Semester: semester}
+				byNIM[n.NIM] = k
+			}
+			mutu, ok := scale.Mutu(n.NilHuruf)
+			k.MK = append(k.MK, KHSItem{
+				KodeMK:   mk.KodeMK,
+				Namamk:   mk.Namamk,
+				Kelas:    mk.Kelas,
+				SKS:      sks,
+				NilAngka: n.NilAngka,
+				NilHuruf: n.NilHuruf,
+				Mutu:     mutu,
+				Dihitung: ok && sks > 0,
+			})
+		}
+	}
+
+	list := make([]KHS, 0, len(byNIM))
+	for _, k := range byNIM {
+		k.TotalSKS, k.IPS = indeksPrestasi(k.MK)
+		list = append(list, *k)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NIM < list[j].NIM })
+	return list
+}
+
+// indeksPrestasi returns the total SKS and Σ(sks × mutu) / Σ sks of the counted items
+func indeksPrestasi(items []KHSItem) (int, float64) {
+	var sks int
+	var bobot float64
+	for _, it := range items {
+		if !it.Dihitung {
+			continue
+		}
+		sks += it.SKS
+		bobot += float64(it.SKS) * it.Mutu
+	}
+	if sks == 0 {
+		return 0, 0
+	}
+	return sks, round2(bobot / float64(sks))
+}
+
+// writeKHS writes the per-student results as "KHS" JSON and spreadsheet
+func writeKHS(cfg *Config, out mkOutput, list []KHS) ([]string, error) {
+	ips := [][]interface{}{{"NIM", "Nama", "Semester", "Jumlah MK", "Total SKS", "IPS"}}
+	detail := [][]interface{}{{"NIM", "Nama", "Kode MK", "Nama MK", "Kelas", "SKS", "Nilai Angka", "Nilai Huruf", "Mutu"}}
+	for _, k := range list {
+		ips = append(ips, []interface{}{k.NIM, k.Nama, k.Semester, len(k.MK), k.TotalSKS, k.IPS})
+		for _, it := range k.MK {
+			detail = append(detail, []interface{}{k.NIM, k.Nama, it.KodeMK, it.Namamk, it.Kelas, it.SKS, it.NilAngka, it.NilHuruf, it.Mutu})
+		}
+	}
+	return writeReport(cfg, out, "KHS", list,
+		sheetTable{Name: "IPS", Rows: ips},
+		sheetTable{Name: "KHS", Rows: detail},
+	)
+}
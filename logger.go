@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// Log output formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logLevels maps the labels used by log()/logf() to slog levels. Banners
+// and step labels are info, so LOG_LEVEL, LOG_FORMAT and LOG_FILE apply to
+// them too.
+var logLevels = map[string]slog.Level{
+	LogDebug:    slog.LevelDebug,
+	LogInfo:     slog.LevelInfo,
+	LogWarn:     slog.LevelWarn,
+	LogError:    slog.LevelError,
+	LogWelcome:  slog.LevelInfo,
+	LogScraping: slog.LevelInfo,
+}
+
+// Until setupLogging runs, log to the terminal at info level
+func init() {
+	slog.SetDefault(slog.New(newConsoleHandler(terminal, slog.LevelInfo)))
+}
+
+// setupLogging configures the default slog logger from LOG_LEVEL, LOG_FORMAT,
+// LOG_FILE, LOG_MAX_SIZE_MB and LOG_MAX_BACKUPS. The returned closer flushes
+// the log file, if any.
+func setupLogging() (io.Closer, error) {
+	_ = godotenv.Load()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(getEnv("LOG_LEVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("LOG_LEVEL tidak valid: %s", os.Getenv("LOG_LEVEL"))
+	}
+	format := strings.ToLower(getEnv("LOG_FORMAT", LogFormatText))
+	if format != LogFormatText && format != LogFormatJSON {
+		return nil, fmt.Errorf("LOG_FORMAT tidak valid: %s (pilih %s atau %s)", format, LogFormatText, LogFormatJSON)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handlers []slog.Handler
+	if format == LogFormatJSON {
+		handlers = append(handlers, slog.NewJSONHandler(terminal, opts))
+	} else {
+		handlers = append(handlers, newConsoleHandler(terminal, level))
+	}
+
+	var closer io.Closer = nopCloser{}
+	if path := os.Getenv("LOG_FILE"); path != "" {
+		maxMB, err := strconv.Atoi(getEnv("LOG_MAX_SIZE_MB", "10"))
+		if err != nil || maxMB <= 0 {
+			return nil, fmt.Errorf("LOG_MAX_SIZE_MB tidak valid: %s", os.Getenv("LOG_MAX_SIZE_MB"))
+		}
+		backups, err := strconv.Atoi(getEnv("LOG_MAX_BACKUPS", "5"))
+		if err != nil || backups < 0 {
+			return nil, fmt.Errorf("LOG_MAX_BACKUPS tidak valid: %s", os.Getenv("LOG_MAX_BACKUPS"))
+		}
+		file, err := openRotatingFile(path, int64(maxMB)<<20, backups)
+		if err != nil {
+			return nil, err
+		}
+		if format == LogFormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(file, opts))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(file, opts))
+		}
+		closer = file
+	}
+
+	slog.SetDefault(slog.New(multiHandler(handlers)))
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// consoleHandler prints records as "[LEVEL] message key=value", the layout
+// the scraper has always used on the terminal
+type consoleHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString("[" + r.Level.String() + "] " + r.Message)
+	appendAttr := func(a slog.Attr) bool {
+		v := a.Value.Resolve().String()
+		if strings.ContainsAny(v, " \"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(" " + a.Key + "=" + v)
+		return true
+	}
+	for _, a := range h.attrs {
+		appendAttr(a)
+	}
+	r.Attrs(appendAttr)
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+// WithGroup is not needed by the console output, groups are flattened
+func (h *consoleHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// multiHandler sends every record to all handlers that accept its level
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// rotatingFile is an append-only log file that is renamed to path.1, path.2, ...
+// once it grows past maxSize. Only the newest backups files are kept.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("gagal buka file log %s: %w", rf.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file, rf.size = file, info.Size()
+	return nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	if rf.backups == 0 {
+		os.Remove(rf.path)
+	} else {
+		for i := rf.backups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+		}
+		if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+			return err
+		}
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
@@ -8,13 +8,14 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/xuri/excelize/v2"
 )
 
-func processMHS(scraper *Scraper, jur Jurusan, semester string) error {
+// processMHS scrapes the mahasiswa of a jurusan, keeping only those who
+// enrolled in tahun (kosong = semua tahun)
+func processMHS(scraper *Scraper, jur Jurusan, semester, tahun string, run *Run) error {
 	// Set prodi sesuai jurusan dan semester
-	if err := scraper.SetProdi(jur.KodeJrs, RegValue, semester); err != nil {
+	if err := scraper.SetProdi(jur.KodeJrs, scraper.config.RegValue, semester); err != nil {
+		notifySetProdiFailed(scraper.config, jur, semester, err)
 		return fmt.Errorf("gagal set prodi untuk jurusan %s: %w", jur.NamaJrs, err)
 	}
 
@@ -34,10 +35,7 @@ func processMHS(scraper *Scraper, jur Jurusan, semester string) error {
 	}
 
 	// Filter mahasiswa berdasarkan tahun masuk (optional)
-	filteredMhsList, tahun, err := filterMahasiswaByYear(mhsList)
-	if err != nil {
-		return fmt.Errorf("gagal filter mahasiswa: %w", err)
-	}
+	filteredMhsList, tahun := filterMahasiswaByYear(mhsList, tahun)
 
 	// Kalau setelah filter tidak ada mahasiswa
 	if len(filteredMhsList) == 0 {
@@ -46,8 +44,8 @@ func processMHS(scraper *Scraper, jur Jurusan, semester string) error {
 	}
 
 	// Siapkan folder penyimpanan JSON & Excel
-	folderJSON := filepath.Join(JSONFolder, jur.NamaJrs, "Mahasiswa")
-	folderExcel := filepath.Join(ExcelFolder, jur.NamaJrs, "Mahasiswa")
+	folderJSON := filepath.Join(scraper.config.JSONFolder, jur.NamaJrs, "Mahasiswa")
+	folderExcel := filepath.Join(scraper.config.ExcelFolder, jur.NamaJrs, "Mahasiswa")
 	if err := os.MkdirAll(folderJSON, os.ModePerm); err != nil {
 		return fmt.Errorf("gagal buat folder JSON: %w", err)
 	}
@@ -66,11 +64,12 @@ func processMHS(scraper *Scraper, jur Jurusan, semester string) error {
 		return fmt.Errorf("gagal tulis JSON untuk jurusan %s: %w", jur.NamaJrs, err)
 	}
 	// Write Excel file
-	excelPath := filepath.Join(folderExcel, namaFile+" "+tahun+".xlsx")
+	excelPath := filepath.Join(folderExcel, namaFile+" "+tahun+sheetExt(scraper.config.SheetFormat))
 	if err := writeExcelMHS(excelPath, filteredMhsList); err != nil {
 		return fmt.Errorf("gagal tulis Excel untuk jurusan %s: %w", jur.NamaJrs, err)
 	}
 
+	run.AddFiles(jsonPath, excelPath)
 	logf(LogInfo, "Berhasil simpan data mahasiswa ke: %s", jsonPath)
 	logf(LogInfo, "Berhasil simpan data mahasiswa ke: %s", excelPath)
 
@@ -96,119 +95,99 @@ func writeJSONMHS(path string, data interface{}) error {
 	return enc.Encode(data)
 }
 
-func writeExcelMHS(path string, data []Mahasiswa) error {
-	xlsx := excelize.NewFile()
-	sheet := "Sheet1"
+// mahasiswaHeaders are the column labels of the mahasiswa worksheet
+var mahasiswaHeaders = []string{
+	"NIM", "Nama", "Tempat Lahir", "Tanggal Lahir", "Jenis Kelamin",
+	"NIK", "Agama", "NISN", "Jalur Pendaftaran", "NPWP",
+	"Kewarganegaraan", "Jenis Pendaftaran", "Tanggal Masuk Kuliah", "Mulai Semester", "Jalan",
+	"RT", "RW", "Nama Dusun", "Kelurahan", "Kecamatan",
+	"Kode Pos", "Jenis Tinggal", "Alat Transportasi", "Telp Rumah", "No HP",
+	"Email", "Terima KPS", "No KPS", "NIK Ayah", "Nama Ayah",
+	"Tanggal Lahir Ayah", "Pendidikan Ayah", "Pekerjaan Ayah", "Penghasilan Ayah", "NIK Ibu",
+	"Nama Ibu", "Tanggal Lahir Ibu", "Pendidikan Ibu", "Pekerjaan Ibu", "Penghasilan Ibu",
+	"Nama Wali", "Tanggal Lahir Wali", "Pendidikan Wali", "Pekerjaan Wali", "Penghasilan Wali",
+	"Kode Prodi", "Nama Prodi", "SKS Diakui", "Kode PT Asal", "Nama PT Asal",
+	"Kode Prodi Asal", "Nama Prodi Asal", "Jenis Pembiayaan", "Jumlah Biaya Masuk",
+}
 
-	textStyle, err := xlsx.NewStyle(&excelize.Style{
-		NumFmt: 49,
-	})
-	if err != nil {
-		return err
+func mahasiswaRow(mhs Mahasiswa) []interface{} {
+	tanggalLahir, _ := parseDate(mhs.TanggalLahir)
+	// tanggalMasuk, _ := parseDate(mhs.TanggalMasuk)
+	tanggalLahirAyah := mhs.TanggalLahirAyah
+	tanggalLahirIbu := mhs.TanggalLahirIbu
+	if mhs.TanggalLahirAyah != "" {
+		tanggalLahirAyah, _ = parseDate(mhs.TanggalLahirAyah)
 	}
-
-	// Define headers
-	headers := []string{
-		"NIM", "Nama", "Tempat Lahir", "Tanggal Lahir", "Jenis Kelamin",
-		"NIK", "Agama", "NISN", "Jalur Pendaftaran", "NPWP",
-		"Kewarganegaraan", "Jenis Pendaftaran", "Tanggal Masuk Kuliah", "Mulai Semester", "Jalan",
-		"RT", "RW", "Nama Dusun", "Kelurahan", "Kecamatan",
-		"Kode Pos", "Jenis Tinggal", "Alat Transportasi", "Telp Rumah", "No HP",
-		"Email", "Terima KPS", "No KPS", "NIK Ayah", "Nama Ayah",
-		"Tanggal Lahir Ayah", "Pendidikan Ayah", "Pekerjaan Ayah", "Penghasilan Ayah", "NIK Ibu",
-		"Nama Ibu", "Tanggal Lahir Ibu", "Pendidikan Ibu", "Pekerjaan Ibu", "Penghasilan Ibu",
-		"Nama Wali", "Tanggal Lahir Wali", "Pendidikan Wali", "Pekerjaan Wali", "Penghasilan Wali",
-		"Kode Prodi", "Nama Prodi", "SKS Diakui", "Kode PT Asal", "Nama PT Asal",
-		"Kode Prodi Asal", "Nama Prodi Asal", "Jenis Pembiayaan", "Jumlah Biaya Masuk",
+	if mhs.TanggalLahirIbu != "" {
+		tanggalLahirIbu, _ = parseDate(mhs.TanggalLahirIbu)
 	}
 
-	// Set headers
-	for i, h := range headers {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		xlsx.SetCellValue(sheet, cell, h)
+	return []interface{}{
+		mhs.NIM,                     // NIM
+		mhs.Nama,                    // Nama
+		mhs.TempatLahir,             // Tempat Lahir
+		tanggalLahir,                // Tanggal Lahir
+		mhs.Gender,                  // Jenis Kelamin
+		mhs.NoKTP,                   // NIK
+		mhs.KodeAgama,               // Agama
+		mhs.ASNIMMSMHS,              // NISN
+		mhs.IDJalurMasuk,            // Jalur Pendaftaran
+		mhs.IdNPWPMhs,               // NPWP
+		"ID",                        // Kewarganegaraan
+		mhs.IDJnsDaftar,             // Jenis Pendaftaran
+		mhs.TanggalMasuk,            // Tanggal Masuk Kuliah
+		mhs.PeriodeSMTHN,            // Mulai Semester
+		mhs.Jalan,                   // Jalan
+		mhs.RT,                      // RT
+		mhs.RW,                      // RW
+		mhs.Dusun,                   // Nama Dusun
+		mhs.Kelurahan,               // Kelurahan
+		mhs.IDWilayah,               // Kecamatan
+		mhs.KodePos,                 // Kode Pos
+		mhs.IDJnsTinggal,            // Jenis Tinggal
+		mhs.IDAlatTransport,         // Alat Transportasi
+		mhs.Telepon,                 // Telp Rumah
+		"0" + mhs.HP1,               // No HP
+		mhs.Email,                   // Email
+		mhs.IDKPS,                   // Terima KPS
+		"",                          // No KPS
+		mhs.NikAyah,                 // NIK Ayah
+		mhs.NamaAyah,                // Nama Ayah
+		tanggalLahirAyah,            // Tanggal Lahir Ayah
+		mhs.IdDidikAyah,             // Pendidikan Ayah
+		mhs.IdKerjaAyah,             // Pekerjaan Ayah
+		mhs.IdPenghasilanAyah,       // Penghasilan Ayah
+		mhs.NikIbu,                  // NIK Ibu
+		mhs.NamaIbu,                 // Nama Ibu
+		tanggalLahirIbu,             // Tanggal Lahir Ibu
+		mhs.IdDidikIbu,              // Pendidikan Ibu
+		mhs.IdKerjaIbu,              // Pekerjaan Ibu
+		mhs.IdPenghasilanIbu,        // Penghasilan Ibu
+		"",                          // Nama Wali
+		"",                          // Tanggal Lahir Wali
+		"",                          // Pendidikan Wali
+		"",                          // Pekerjaan Wali
+		"",                          // Penghasilan Wali
+		mhs.KodeJrs,                 // Kode Prodi
+		mhs.NamaJrs,                 // Nama Prodi
+		"",                          // SKS Diakui
+		mhs.IDPerguruanTinggiAsal,   // Kode PT Asal
+		mhs.NamaPerguruanTinggiAsal, // Nama PT Asal
+		mhs.IDProdiAsal,             // Kode Prodi Asal
+		mhs.NamaProgramStudiAsal,    // Nama Prodi Asal
+		mhs.IDPembiayaan,            // Jenis Pembiayaan
+		mhs.BiayaMasuk,              // Jumlah Biaya Masuk
 	}
+}
 
-	// Set data rows
-	for i, mhs := range data {
-		row := i + 2
-		tanggalLahir, _ := parseDate(mhs.TanggalLahir)
-		// tanggalMasuk, _ := parseDate(mhs.TanggalMasuk)
-		tanggalLahirAyah := mhs.TanggalLahirAyah
-		tanggalLahirIbu := mhs.TanggalLahirIbu
-		if mhs.TanggalLahirAyah != "" {
-			tanggalLahirAyah, _ = parseDate(mhs.TanggalLahirAyah)
-		}
-		if mhs.TanggalLahirIbu != "" {
-			tanggalLahirIbu, _ = parseDate(mhs.TanggalLahirIbu)
-		}
-
-		vals := []interface{}{
-			mhs.NIM,                     // NIM
-			mhs.Nama,                    // Nama
-			mhs.TempatLahir,             // Tempat Lahir
-			tanggalLahir,                // Tanggal Lahir
-			mhs.Gender,                  // Jenis Kelamin
-			mhs.NoKTP,                   // NIK
-			mhs.KodeAgama,               // Agama
-			mhs.ASNIMMSMHS,              // NISN
-			mhs.IDJalurMasuk,            // Jalur Pendaftaran
-			mhs.IdNPWPMhs,               // NPWP
-			"ID",                        // Kewarganegaraan
-			mhs.IDJnsDaftar,             // Jenis Pendaftaran
-			mhs.TanggalMasuk,            // Tanggal Masuk Kuliah
-			mhs.PeriodeSMTHN,            // Mulai Semester
-			mhs.Jalan,                   // Jalan
-			mhs.RT,                      // RT
-			mhs.RW,                      // RW
-			mhs.Dusun,                   // Nama Dusun
-			mhs.Kelurahan,               // Kelurahan
-			mhs.IDWilayah,               // Kecamatan
-			mhs.KodePos,                 // Kode Pos
-			mhs.IDJnsTinggal,            // Jenis Tinggal
-			mhs.IDAlatTransport,         // Alat Transportasi
-			mhs.Telepon,                 // Telp Rumah
-			"0" + mhs.HP1,               // No HP
-			mhs.Email,                   // Email
-			mhs.IDKPS,                   // Terima KPS
-			"",                          // No KPS
-			mhs.NikAyah,                 // NIK Ayah
-			mhs.NamaAyah,                // Nama Ayah
-			tanggalLahirAyah,            // Tanggal Lahir Ayah
-			mhs.IdDidikAyah,             // Pendidikan Ayah
-			mhs.IdKerjaAyah,             // Pekerjaan Ayah
-			mhs.IdPenghasilanAyah,       // Penghasilan Ayah
-			mhs.NikIbu,                  // NIK Ibu
-			mhs.NamaIbu,                 // Nama Ibu
-			tanggalLahirIbu,             // Tanggal Lahir Ibu
-			mhs.IdDidikIbu,              // Pendidikan Ibu
-			mhs.IdKerjaIbu,              // Pekerjaan Ibu
-			mhs.IdPenghasilanIbu,        // Penghasilan Ibu
-			"",                          // Nama Wali
-			"",                          // Tanggal Lahir Wali
-			"",                          // Pendidikan Wali
-			"",                          // Pekerjaan Wali
-			"",                          // Penghasilan Wali
-			mhs.KodeJrs,                 // Kode Prodi
-			mhs.NamaJrs,                 // Nama Prodi
-			"",                          // SKS Diakui
-			mhs.IDPerguruanTinggiAsal,   // Kode PT Asal
-			mhs.NamaPerguruanTinggiAsal, // Nama PT Asal
-			mhs.IDProdiAsal,             // Kode Prodi Asal
-			mhs.NamaProgramStudiAsal,    // Nama Prodi Asal
-			mhs.IDPembiayaan,            // Jenis Pembiayaan
-			mhs.BiayaMasuk,              // Jumlah Biaya Masuk
-		}
-
-		for j, v := range vals {
-			cell, _ := excelize.CoordinatesToCellName(j+1, row)
-			xlsx.SetCellValue(sheet, cell, v)
-
-			// Apply text style to all columns
-			xlsx.SetCellStyle(sheet, cell, cell, textStyle)
-		}
+// writeExcelMHS writes the mahasiswa sheet as .xlsx or .ods depending on the path extension
+func writeExcelMHS(path string, data []Mahasiswa) error {
+	rows := [][]interface{}{headerRow(mahasiswaHeaders)}
+	for _, mhs := range data {
+		rows = append(rows, mahasiswaRow(mhs))
 	}
-
-	return xlsx.SaveAs(path)
+	// Apply text style to all columns
+	return writeTables(path, sheetTable{Name: "Sheet1", Rows: rows, Text: true})
 }
 
 func parseDate(date string) (string, error) {
@@ -235,8 +214,8 @@ func parseDate(date string) (string, error) {
 	return t.Format("2006-01-02"), nil
 }
 
-// filterMahasiswaByYear filters mahasiswa based on enrollment year
-func filterMahasiswaByYear(mhsList []Mahasiswa) ([]Mahasiswa, string, error) {
+// askTahunMasuk asks for the enrollment year filter; "" means semua tahun
+func askTahunMasuk() (string, error) {
 	// Tampilkan opsi filter
 	fmt.Println()
 	fmt.Println("=================================")
@@ -250,45 +229,53 @@ func filterMahasiswaByYear(mhsList []Mahasiswa) ([]Mahasiswa, string, error) {
 	fmt.Printf("[INFO] Pilih opsi filter (1-2): ")
 	_, err := fmt.Scan(&pilihan)
 	if err != nil {
-		return nil, "Semua Tahun", fmt.Errorf("gagal membaca input filter: %w", err)
+		return "", fmt.Errorf("gagal membaca input filter: %w", err)
 	}
 
 	switch pilihan {
 	case 1:
-		// Tidak ada filter, return semua data
-		logf(LogInfo, "Filter: Mengambil semua data mahasiswa")
-		return mhsList, "Semua Tahun", nil
+		return "", nil
 
 	case 2:
-		// Filter berdasarkan tahun
 		var tahunFilter string
 		fmt.Printf("[INFO] Masukkan tahun masuk (contoh: 2025, 2024, 2023): ")
 		_, err := fmt.Scan(&tahunFilter)
 		if err != nil {
-			return nil, string(tahunFilter), fmt.Errorf("gagal membaca input tahun: %w", err)
+			return "", fmt.Errorf("gagal membaca input tahun: %w", err)
 		}
 
 		// Validasi tahun
 		if _, err := strconv.Atoi(tahunFilter); err != nil {
-			return nil, string(tahunFilter), fmt.Errorf("tahun tidak valid: %s", tahunFilter)
+			return "", fmt.Errorf("tahun tidak valid: %s", tahunFilter)
 		}
+		return tahunFilter, nil
 
-		logf(LogInfo, "Filter: Mengambil data mahasiswa tahun %s", tahunFilter)
+	default:
+		return "", fmt.Errorf("pilihan filter tidak valid: %d", pilihan)
+	}
+}
 
-		// Filter mahasiswa berdasarkan tahun masuk
-		var filteredList []Mahasiswa
-		for _, mhs := range mhsList {
-			// Parse tanggal masuk (format: "2025-09-01")
-			if strings.HasPrefix(mhs.TanggalMasuk, tahunFilter) {
-				filteredList = append(filteredList, mhs)
-			}
-		}
+// filterMahasiswaByYear filters mahasiswa based on enrollment year and
+// returns the label used in the output file names
+func filterMahasiswaByYear(mhsList []Mahasiswa, tahunFilter string) ([]Mahasiswa, string) {
+	if tahunFilter == "" {
+		// Tidak ada filter, return semua data
+		logf(LogInfo, "Filter: Mengambil semua data mahasiswa")
+		return mhsList, "Semua Tahun"
+	}
 
-		logf(LogInfo, "Ditemukan %d mahasiswa dari tahun %s (dari %d total)", len(filteredList), tahunFilter, len(mhsList))
-		return filteredList, string(tahunFilter), nil
+	logf(LogInfo, "Filter: Mengambil data mahasiswa tahun %s", tahunFilter)
 
-	default:
-		return nil, "Semua Tahun", fmt.Errorf("pilihan filter tidak valid: %d", pilihan)
+	// Filter mahasiswa berdasarkan tahun masuk
+	var filteredList []Mahasiswa
+	for _, mhs := range mhsList {
+		// Parse tanggal masuk (format: "2025-09-01")
+		if strings.HasPrefix(mhs.TanggalMasuk, tahunFilter) {
+			filteredList = append(filteredList, mhs)
+		}
 	}
+
+	logf(LogInfo, "Ditemukan %d mahasiswa dari tahun %s (dari %d total)", len(filteredList), tahunFilter, len(mhsList))
+	return filteredList, tahunFilter
 }
 // no changes
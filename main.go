@@ -2,21 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"time"
 )
 
 const (
-	// Folder paths
-	JSONFolder  = "nilai_json"
-	ExcelFolder = "nilai_excel"
-
-	// File names
-	CookieFile    = "cookie.txt"
-	JurusanFile   = "jurusan.json"
-	MediaEndpoint = "/media.php"
-	IndexEndpoint = "/index.php"
-	LoginEndpoint = "/ceklogin.php?h="
-
 	// HTTP methods
 	GET  = "GET"
 	POST = "POST"
@@ -39,61 +29,21 @@ const (
 	AcceptJSON     = "application/json, text/javascript, */*; q=0.01"
 	CharsetUTF8    = "; charset=UTF-8"
 
-	// Form fields
-	FormUsername       = "username"
-	FormPassword       = "password"
-	FormValidation     = "validation"
-	FormHideValidation = "hide_validation"
-	FormHideIP         = "hide_ipnya"
-	FormParam          = "param"
-	FormCetak          = "cetak"
-	FormPS             = "ps"
-	FormPK             = "pk"
-	FormSMTHN          = "smthn"
-
-	// Cookie names
-	CookiePHPSESSID = "PHPSESSID"
-
 	// Default values
 	DefaultIP  = "182.8.179.9"
-	RegValue   = "REG"
 	CetakValue = "1"
 	FakValue   = "1" // Default faculty value
 
-	// Excel headers
-	ExcelNIM       = "Nim"
-	ExcelNama      = "Nama Mahasiswa"
-	ExcelKodeMK    = "Kode Mata Kuliah"
-	ExcelNamaMK    = "Nama Mata Kuliah"
-	ExcelSemester  = "Semester"
-	ExcelKelas     = "Nama Kelas"
-	ExcelAngka     = "Angka"
-	ExcelHuruf     = "Huruf"
-	ExcelKehadiran = "Aktivitas Partisipatif"
-	ExcelProjek    = "Hasil Proyek"
-	ExcelQuiz      = "Kognitif/ Pengetahuan Quiz"
-	ExcelTugas     = "Kognitif/ Pengetahuan Tugas"
-	ExcelUTS       = "Kognitif/ Pengetahuan Ujian Tengah Semester"
-	ExcelUAS       = "Kognitif/ Pengetahuan Ujian Akhir Semester"
-	ExcelKodePK    = "Kode Prodi Mahasiswa"
-	ExcelNamaPK    = "Nama Prodi Mahasiswa"
-	ExcelKodeProdi = "Kode Prodi Kelas"
-	ExcelNamaProdi = "Nama Prodi Kelas"
-
 	// Progress bar
 	ProgressBarLength = 40
 
 	// Log levels
-	LogInfo    = "[INFO]"
-	LogError   = "[ERROR]"
-	LogWarn    = "[WARN]"
-	LogDebug   = "[DEBUG]"
-	LogWelcome = "[WELCOME]"
-)
-
-var (
-	// baseURL string
-	cookie string
+	LogInfo     = "[INFO]"
+	LogError    = "[ERROR]"
+	LogWarn     = "[WARN]"
+	LogDebug    = "[DEBUG]"
+	LogWelcome  = "[WELCOME]"
+	LogScraping = "[SCRAPING]"
 )
 
 // User agent list (tidak berubah)
@@ -108,7 +58,31 @@ var userAgents = []string{
 	"Mozilla/5.0 (Macintosh; Intel Mac OS X 13_5_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36",
 }
 
+// modeNames maps the interactive menu choice to the run mode
+var modeNames = map[int]string{1: ModeNilai, 2: ModeMahasiswa, 3: ModeKeduanya}
+
 func main() {
+	args := parseGlobalFlags(os.Args[1:])
+	logFile, err := setupLogging()
+	if err != nil {
+		logf(LogError, "Gagal menyiapkan log: %v", err)
+		os.Exit(1)
+	}
+	defer logFile.Close()
+	startMetricsServer()
+
+	// --- Mode perintah (mis. "ipk") ---
+	if len(args) > 0 {
+		err := runCommand(args[0], args[1:])
+		waitWebhooks(WebhookWait)
+		if err != nil {
+			logf(LogError, "%v", err)
+			logFile.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
 	// --- Input username & password ---
 	config, err := LoadConfig()
 
@@ -124,10 +98,19 @@ func main() {
 	// Create scraper instance
 	scraper := NewScraper(config)
 
+	run := newRun(config)
+	err = runInteractive(scraper, run)
+	finishRun(config, run, err)
+	waitWebhooks(WebhookWait)
+}
+
+// runInteractive asks for semester, jurusan and mode, then scrapes. Every
+// failure is logged where it happens and returned so the run can record it.
+func runInteractive(scraper *Scraper, run *Run) error {
 	// Handle authentication
 	if err := handleAuthentication(scraper); err != nil {
 		logf(LogError, "Gagal autentikasi: %v", err)
-		return
+		return fmt.Errorf("gagal autentikasi: %w", err)
 	}
 	logf(LogInfo, "Login Sebagai: %s", scraper.config.Username)
 	fmt.Println()
@@ -136,15 +119,16 @@ func main() {
 	semester, err := scraper.SelectSemester()
 	if err != nil {
 		logf(LogError, "Gagal memilih semester: %v", err)
-		return
+		return fmt.Errorf("gagal memilih semester: %w", err)
 	}
 	logf(LogInfo, "Semester dipilih: %s", semester)
+	run.SetSemester(semester)
 	fmt.Println()
 	// --- Load jurusan ---
-	jurusan, err := loadJurusan()
+	jurusan, err := loadJurusan(scraper.config)
 	if err != nil {
 		logf(LogError, "Gagal load jurusan: %v", err)
-		return
+		return fmt.Errorf("gagal load jurusan: %w", err)
 	}
 	fmt.Println()
 
@@ -159,42 +143,52 @@ func main() {
 
 	var pilihan int
 	fmt.Printf("[INFO] Pilih opsi (1-3): ")
-	_, err = fmt.Scan(&pilihan)
-	if err != nil {
+	if _, err := fmt.Scan(&pilihan); err != nil {
 		logf(LogError, "Gagal membaca input: %v", err)
-		return
+		return fmt.Errorf("gagal membaca input: %w", err)
 	}
 
 	if pilihan < 1 || pilihan > 3 {
 		logf(LogError, "Pilihan invalid: %d. Pilih antara 1-3", pilihan)
-		return
+		return fmt.Errorf("pilihan invalid: %d", pilihan)
+	}
+
+	// filter tahun masuk ditanya sebelum scraping agar run tidak berhenti di tengah
+	var tahun string
+	if pilihan == 2 || pilihan == 3 {
+		if tahun, err = askTahunMasuk(); err != nil {
+			logf(LogError, "Gagal filter mahasiswa: %v", err)
+			return fmt.Errorf("gagal filter mahasiswa: %w", err)
+		}
 	}
 
 	fmt.Println()
+	run.AddJurusan(jurusan)
+	run.SetMode(modeNames[pilihan])
 	start := time.Now()
 	// --- Proses scraping sesuai pilihan ---
 	switch pilihan {
 	case 1:
 		logf(LogInfo, "Memulai scraping Nilai Mata Kuliah...")
-		if err := processJurusan(scraper, jurusan, semester); err != nil {
+		if err := processJurusan(scraper, jurusan, semester, run); err != nil {
 			logf(LogError, "Gagal proses jurusan: %v", err)
-			return
+			return fmt.Errorf("gagal proses jurusan: %w", err)
 		}
 	case 2:
 		logf(LogInfo, "Memulai scraping Data Mahasiswa...")
-		if err := processMHS(scraper, jurusan, semester); err != nil {
+		if err := processMHS(scraper, jurusan, semester, tahun, run); err != nil {
 			logf(LogError, "Gagal proses Mahasiswa: %v", err)
-			return
+			return fmt.Errorf("gagal proses mahasiswa: %w", err)
 		}
 	case 3:
 		logf(LogInfo, "Memulai scraping Keduanya...")
-		if err := processJurusan(scraper, jurusan, semester); err != nil {
+		if err := processJurusan(scraper, jurusan, semester, run); err != nil {
 			logf(LogError, "Gagal proses jurusan: %v", err)
-			return
+			return fmt.Errorf("gagal proses jurusan: %w", err)
 		}
-		if err := processMHS(scraper, jurusan, semester); err != nil {
+		if err := processMHS(scraper, jurusan, semester, tahun, run); err != nil {
 			logf(LogError, "Gagal proses Mahasiswa: %v", err)
-			return
+			return fmt.Errorf("gagal proses mahasiswa: %w", err)
 		}
 	}
 	elapsed := time.Since(start)
@@ -203,6 +197,7 @@ func main() {
 	log(LogInfo, "Semua data berhasil disimpan di folder")
 	logf(LogInfo, "Waktu yang dibutuhkan: %s", formatDuration(elapsed))
 	fmt.Println("=====================================================================")
+	return nil
 }
 
 // func setHeaders(req *http.Request) {
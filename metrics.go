@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsPath is where the Prometheus endpoint is served when METRICS_ADDR is set
+const MetricsPath = "/metrics"
+
+// latencyBuckets are the upper bounds, in seconds, of the request latency histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collected during a run, exported in the Prometheus text format
+var (
+	metricRequests = newCounterVec("siakad_requests_total",
+		"Request ke SIAKAD per endpoint dan status HTTP (error = gagal kirim).", "endpoint", "status")
+	metricLatency = newHistogramVec("siakad_request_duration_seconds",
+		"Latensi request ke SIAKAD per endpoint.", latencyBuckets, "endpoint")
+	metricRetries = newCounterVec("scraper_retries_total",
+		"MK yang di-scrape ulang oleh perintah retry.")
+	metricMK = newCounterVec("scraper_mk_total",
+		"MK yang diproses per hasil (ok/failed).", "result")
+	metricBytes = newCounterVec("scraper_bytes_written_total",
+		"Byte file output MK yang ditulis.")
+
+	allMetrics = []metric{metricRequests, metricLatency, metricRetries, metricMK, metricBytes}
+)
+
+type metric interface {
+	writeTo(w io.Writer)
+	// snapshot returns a copy of the current values
+	snapshot() metric
+	// since returns the values added after base, a snapshot of the same metric
+	since(base metric) metric
+}
+
+// metricsSnapshot holds the values of allMetrics at one moment. The metrics
+// are process totals; a run subtracts the snapshot taken when it started so
+// its runs/<id>.prom only counts that run in serve and daemon mode.
+type metricsSnapshot []metric
+
+func takeMetricsSnapshot() metricsSnapshot {
+	s := make(metricsSnapshot, len(allMetrics))
+	for i, m := range allMetrics {
+		s[i] = m.snapshot()
+	}
+	return s
+}
+
+// counterVec is a counter partitioned by label values
+type counterVec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+// Add increases the counter of the given label values by v
+func (c *counterVec) Add(v float64, labelValues ...string) {
+	key := metricKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+func (c *counterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *counterVec) snapshot() metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp := newCounterVec(c.name, c.help, c.labels...)
+	for k, v := range c.values {
+		cp.values[k] = v
+	}
+	return cp
+}
+
+func (c *counterVec) since(base metric) metric {
+	b := base.(*counterVec)
+	d := c.snapshot().(*counterVec)
+	for k, v := range d.values {
+		if v -= b.values[k]; v != 0 {
+			d.values[k] = v
+		} else {
+			delete(d.values, k)
+		}
+	}
+	return d
+}
+
+func (c *counterVec) writeTo(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+	}
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, key, formatMetric(c.values[key]))
+	}
+}
+
+// histogramVec is a histogram partitioned by label values
+type histogramVec struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogram{}}
+}
+
+// Observe records v for the given label values
+func (h *histogramVec) Observe(v float64, labelValues ...string) {
+	key := metricKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, le := range h.buckets {
+		if v <= le {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *histogramVec) snapshot() metric {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cp := newHistogramVec(h.name, h.help, h.buckets, h.labels...)
+	for k, s := range h.series {
+		cp.series[k] = &histogram{counts: append([]uint64(nil), s.counts...), count: s.count, sum: s.sum}
+	}
+	return cp
+}
+
+func (h *histogramVec) since(base metric) metric {
+	b := base.(*histogramVec)
+	d := h.snapshot().(*histogramVec)
+	for k, s := range d.series {
+		old, ok := b.series[k]
+		if !ok {
+			continue
+		}
+		if s.count == old.count {
+			delete(d.series, k)
+			continue
+		}
+		for i := range s.counts {
+			s.counts[i] -= old.counts[i]
+		}
+		s.count -= old.count
+		s.sum -= old.sum
+	}
+	return d
+}
+
+func (h *histogramVec) writeTo(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cum uint64
+		for i, le := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", formatMetric(le)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, key, formatMetric(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, key, s.count)
+	}
+}
+
+// metricKey renders label values as {a="x",b="y"}, which is also the series key
+func metricKey(labels, values []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		parts[i] = l + "=" + strconv.Quote(v)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// withLabel adds one more label to a rendered series key
+func withLabel(key, label, value string) string {
+	extra := label + "=" + strconv.Quote(value)
+	if key == "" {
+		return "{" + extra + "}"
+	}
+	return strings.TrimSuffix(key, "}") + "," + extra + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// writeMetrics writes every metric in the Prometheus text format
+func writeMetrics(w io.Writer) {
+	for _, m := range allMetrics {
+		m.writeTo(w)
+	}
+}
+
+// saveMetrics dumps the metrics added since base to path
+func saveMetrics(path string, base metricsSnapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("gagal buat file metrics %s: %w", path, err)
+	}
+	for i, m := range allMetrics {
+		m.since(base[i]).writeTo(file)
+	}
+	return file.Close()
+}
+
+// observeRequest records the latency and status of one request to endpoint
+func observeRequest(endpoint string, start time.Time, res *http.Response, err error) {
+	metricLatency.Observe(time.Since(start).Seconds(), endpoint)
+	if err != nil {
+		metricRequests.Inc(endpoint, "error")
+		return
+	}
+	metricRequests.Inc(endpoint, strconv.Itoa(res.StatusCode))
+}
+
+// startMetricsServer serves MetricsPath on METRICS_ADDR, if set, for the
+// lifetime of the process
+func startMetricsServer() {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(MetricsPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w)
+	})
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logf(LogError, "Gagal menjalankan endpoint metrics di %s: %v", addr, err)
+		}
+	}()
+	logf(LogInfo, "Metrics tersedia di http://%s%s", addr, MetricsPath)
+}
+
+// meteredWriter counts the bytes written through it in scraper_bytes_written_total
+type meteredWriter struct {
+	w io.Writer
+}
+
+func (m meteredWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	metricBytes.Add(float64(n))
+	return n, err
+}
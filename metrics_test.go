@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMetricsSinceSnapshot(t *testing.T) {
+	requests := newCounterVec("req_total", "", "endpoint")
+	latency := newHistogramVec("lat_seconds", "", []float64{0.1, 1}, "endpoint")
+	requests.Inc("/login")
+	latency.Observe(0.05, "/login")
+
+	base := []metric{requests.snapshot(), latency.snapshot()}
+	requests.Inc("/nilai")
+	requests.Add(2, "/login")
+	latency.Observe(0.5, "/nilai")
+
+	var buf bytes.Buffer
+	requests.since(base[0]).writeTo(&buf)
+	latency.since(base[1]).writeTo(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`req_total{endpoint="/login"} 2`,
+		`req_total{endpoint="/nilai"} 1`,
+		`lat_seconds_count{endpoint="/nilai"} 1`,
+		`lat_seconds_bucket{endpoint="/nilai",le="0.1"} 0`,
+		`lat_seconds_bucket{endpoint="/nilai",le="1"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `lat_seconds_count{endpoint="/login"}`) {
+		t.Errorf("series without new observations is written:\n%s", out)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// IncompleteClass is a class that is not ready for grade publication
+type IncompleteClass struct {
+	KodeMK          string   `json:"kodemk"`
+	Namamk          string   `json:"namamk"`
+	Kelas           string   `json:"kelas"`
+	Namadosen       string   `json:"namadosen"`
+	Status          string   `json:"status"`
+	Mahasiswa       int      `json:"mahasiswa"`
+	NilaiKosong     int      `json:"nilai_kosong"`
+	KomponenKosong  int      `json:"komponen_kosong"`
+	NIMBelumLengkap []string `json:"nim_belum_lengkap,omitempty"`
+}
+
+// IncompleteDosen groups the incomplete classes of one dosen
+type IncompleteDosen struct {
+	Namadosen      string            `json:"namadosen"`
+	Kelas          []IncompleteClass `json:"kelas"`
+	BelumCetak     int               `json:"belum_cetak"`
+	NilaiKosong    int               `json:"nilai_kosong"`
+	KomponenKosong int               `json:"komponen_kosong"`
+}
+
+// Status values of an IncompleteClass
+const (
+	StatusBelumCetak   = "belum cetak"
+	StatusBelumLengkap = "belum lengkap"
+)
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// findIncomplete lists classes with empty nil_huruf/nil_angka or empty components,
+// plus every MK whose cetak is not CetakValue, grouped by dosen. An unpublished
+// MK that was scraped anyway (INCLUDE_UNPUBLISHED) is listed once, with its counts.
+func findIncomplete(all []MataKuliah, results []mkResult) []IncompleteDosen {
+	scraped := map[string]bool{}
+	for _, res := range results {
+		scraped[classKey(res.MataKuliah.KodeMK, res.MataKuliah.Kelas)] = true
+	}
+	var classes []IncompleteClass
+	for _, mk := range all {
+		if mk.Cetak != CetakValue && !scraped[classKey(mk.KodeMK, mk.Kelas)] {
+			classes = append(classes, IncompleteClass{KodeMK: mk.KodeMK, Namamk: mk.Namamk, Kelas: mk.Kelas, Namadosen: mk.Namadosen, Status: StatusBelumCetak})
+		}
+	}
+	for _, res := range results {
+		mk := res.MataKuliah
+		cls := IncompleteClass{KodeMK: mk.KodeMK, Namamk: mk.Namamk, Kelas: mk.Kelas, Namadosen: mk.Namadosen, Status: StatusBelumLengkap, Mahasiswa: len(res.Nilai)}
+		if mk.Cetak != CetakValue {
+			cls.Status = StatusBelumCetak
+		}
+		for _, n := range res.Nilai {
+			nilaiKosong := isBlank(n.NilHuruf) || isBlank(n.NilAngka)
+			komponenKosong := false
+			for _, v := range []string{n.Hadir, n.Projek, n.Quiz, n.Tugas, n.UTS, n.UAS} {
+				if isBlank(v) {
+					komponenKosong = true
+				}
+			}
+			if nilaiKosong {
+				cls.NilaiKosong++
+			}
+			if komponenKosong {
+				cls.KomponenKosong++
+			}
+			if nilaiKosong || komponenKosong {
+				cls.NIMBelumLengkap = append(cls.NIMBelumLengkap, n.NIM)
+			}
+		}
+		if len(cls.NIMBelumLengkap) > 0 || cls.Status == StatusBelumCetak {
+			classes = append(classes, cls)
+		}
+	}
+
+	byDosen := map[string]*IncompleteDosen{}
+	for _, cls := range classes {
+		nama := strings.TrimSpace(cls.Namadosen)
+		if nama == "" {
+			nama = "Tanpa Dosen"
+		}
+		d, ok := byDosen[nama]
+		if !ok {
+			d = &IncompleteDosen{Namadosen: nama}
+			byDosen[nama] = d
+		}
+		d.Kelas = append(d.Kelas, cls)
+		if cls.Status == StatusBelumCetak {
+			d.BelumCetak++
+		}
+		d.NilaiKosong += cls.NilaiKosong
+		d.KomponenKosong += cls.KomponenKosong
+	}
+
+	list := make([]IncompleteDosen, 0, len(byDosen))
+	for _, d := range byDosen {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Namadosen < list[j].Namadosen })
+	return list
+}
+
+// writeIncomplete writes the report as "Nilai Belum Lengkap" JSON and spreadsheet
+func writeIncomplete(cfg *Config, out mkOutput, list []IncompleteDosen) ([]string, error) {
+	ringkasan := [][]interface{}{{"Dosen", "Jumlah Kelas", "Belum Cetak", "Nilai Kosong", "Komponen Kosong"}}
+	detail := [][]interface{}{{"Dosen", "Kode MK", "Nama MK", "Kelas", "Status", "Jumlah Mahasiswa", "Nilai Kosong", "Komponen Kosong", "NIM Belum Lengkap"}}
+	for _, d := range list {
+		ringkasan = append(ringkasan, []interface{}{d.Namadosen, len(d.Kelas), d.BelumCetak, d.NilaiKosong, d.KomponenKosong})
+		for _, k := range d.Kelas {
+			detail = append(detail, []interface{}{d.Namadosen, k.KodeMK, k.Namamk, k.Kelas, k.Status, k.Mahasiswa, k.NilaiKosong, k.KomponenKosong, strings.Join(k.NIMBelumLengkap, ", ")})
+		}
+	}
+	return writeReport(cfg, out, "Nilai Belum Lengkap", list,
+		sheetTable{Name: "Per Dosen", Rows: ringkasan},
+		sheetTable{Name: "Detail", Rows: detail},
+	)
+}
@@ -21,6 +21,7 @@ type MataKuliah struct {
 	KodeJrs   string `json:"kodejrs"`
 	KodeMK    string `json:"kodemk"`
 	KodePK    string `json:"kodepk"`
+	SKS       string `json:"sks"`
 	Smtthnakd string `json:"smtthnakd"`
 	NamaJrs   string `json:"namajrs"`
 }
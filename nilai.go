@@ -5,16 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
-
-	"github.com/xuri/excelize/v2"
+	"time"
 )
 
-const WorkerCount = 5
-
-func processJurusan(scraper *Scraper, jur Jurusan, semester string) error {
-	if err := scraper.SetProdi(jur.KodeJrs, RegValue, semester); err != nil {
+func processJurusan(scraper *Scraper, jur Jurusan, semester string, run *Run) error {
+	if err := scraper.SetProdi(jur.KodeJrs, scraper.config.RegValue, semester); err != nil {
+		notifySetProdiFailed(scraper.config, jur, semester, err)
 		return err
 	}
 
@@ -23,59 +22,267 @@ func processJurusan(scraper *Scraper, jur Jurusan, semester string) error {
 		return err
 	}
 
-	// filter MK cetak=1
+	// filter MK cetak=1, kecuali INCLUDE_UNPUBLISHED aktif
 	var skip int
-	var mkList []MataKuliah
+	var mkList, unpublished []MataKuliah
 	for _, mk := range resp.Rows {
-		if mk.Cetak == "1" {
-			mkList = append(mkList, mk)
-		} else {
-			skip++
+		if mk.Cetak != CetakValue {
+			unpublished = append(unpublished, mk)
+			if !scraper.config.IncludeUnpublished {
+				skip++
+				run.AddMK(MKStatus{Jurusan: jur, Semester: semester, MataKuliah: mk, Status: StatusSkipped, Error: "status cetak = " + mk.Cetak})
+				continue
+			}
 		}
+		mkList = append(mkList, mk)
 	}
 
 	total := len(mkList)
 	if total == 0 {
+		// tetap lanjut agar laporan MK belum cetak tersimpan
 		logf(LogWarn, "Jurusan %s tidak ada MK dengan cetak=1", jur.NamaJrs)
-		return nil
 	}
 	all := len(resp.Rows)
-	folderJSON := filepath.Join(JSONFolder, jur.NamaJrs, semester)
-	folderExcel := filepath.Join(ExcelFolder, jur.NamaJrs, semester)
-	os.MkdirAll(folderJSON, os.ModePerm)
-	os.MkdirAll(folderExcel, os.ModePerm)
+	out, closeOut, err := newMKOutput(scraper, jur, semester)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
 
 	var wg sync.WaitGroup
 	done := 0
-	last := 0
 	mu := sync.Mutex{}
+	var results []mkResult
 	printHeader("Scraping Jurusan", nil)
-	logf("[SCRAPING]", "Mulai scraping jurusan: %s", jur.NamaJrs)
+	logf(LogScraping, "Mulai scraping jurusan: %s", jur.NamaJrs)
+	bar := terminal.Begin(jur.NamaJrs, total)
+	// paling banyak WorkerCount MK diambil bersamaan
+	workers := make(chan struct{}, scraper.config.WorkerCount)
 	for _, mk := range mkList {
 		wg.Add(1)
 		go func(mk MataKuliah) {
 			defer wg.Done()
-			scrapeMK(scraper, mk, folderJSON, folderExcel)
+			workers <- struct{}{}
+			res, st, err := attemptMK(scraper, jur, semester, mk, out)
+			<-workers
+			run.AddMK(st)
 
 			mu.Lock()
+			if err == nil {
+				results = append(results, *res)
+			}
 			done++
-			updateProgress(jur.NamaJrs, done, total, &last)
 			mu.Unlock()
+			bar.Inc()
 		}(mk)
 	}
 
 	wg.Wait()
-	fmt.Println()
-	logf(LogInfo, "Jurusan %s: berhasil simpan %d MK dari %d MK, skip %d MK karena status cetak = 0", jur.NamaJrs, done, all, skip)
+	bar.Done()
+	if err := out.manifest.Save(); err != nil {
+		logf(LogError, "Gagal simpan manifest: %v", err)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].MataKuliah, results[j].MataKuliah
+		if a.KodeMK != b.KodeMK {
+			return a.KodeMK < b.KodeMK
+		}
+		return a.Kelas < b.Kelas
+	})
+	if scraper.config.IncludeUnpublished {
+		logf(LogInfo, "Jurusan %s: berhasil simpan %d MK dari %d MK, termasuk %d MK belum cetak", jur.NamaJrs, len(results), all, len(unpublished))
+	} else {
+		logf(LogInfo, "Jurusan %s: berhasil simpan %d MK dari %d MK, skip %d MK karena status cetak = 0", jur.NamaJrs, len(results), all, skip)
+	}
+	if failed := done - len(results); failed > 0 {
+		logf(LogWarn, "Jurusan %s: %d MK gagal diambil, lihat manifest run", jur.NamaJrs, failed)
+	}
+	unchanged := 0
+	for _, res := range results {
+		if !res.Changed {
+			unchanged++
+		}
+	}
+	changed := out.manifest.Changed()
+	logf(LogInfo, "Jurusan %s: %d MK tidak berubah (tidak ditulis ulang), %d MK berubah sejak run sebelumnya", jur.NamaJrs, unchanged, len(changed))
+	for _, c := range changed {
+		logf(LogInfo, "  berubah: %s", c)
+	}
+	run.AddFiles(writeJurusanReports(scraper, run, jur, semester, out, resp.Rows, unpublished, results)...)
 	return nil
 }
 
-func scrapeMK(scraper *Scraper, mk MataKuliah, folderJSON, folderExcel string) {
+// writeJurusanReports writes every report of a jurusan-semester (audit, MK
+// belum cetak, selisih nilai, validasi huruf, statistik, dosen, KHS, nilai
+// belum lengkap), records the anomalies in run and returns the paths written
+func writeJurusanReports(scraper *Scraper, run *Run, jur Jurusan, semester string, out mkOutput, all, unpublished []MataKuliah, results []mkResult) []string {
+	var files []string
+	collect := func(paths []string, err error) error {
+		files = append(files, paths...)
+		return err
+	}
+	anomaly := func(jenis string, jumlah int, format string, args ...interface{}) {
+		run.AddAnomaly(Anomaly{Jurusan: jur, Semester: semester, Jenis: jenis, Jumlah: jumlah, Keterangan: fmt.Sprintf(format, args...)})
+	}
+
+	if entries := out.audit.Entries(); len(entries) > 0 {
+		paths, err := writeAudit(scraper.config, out, entries, time.Now())
+		if err := collect(paths, err); err != nil {
+			logf(LogError, "Gagal tulis audit nilai: %v", err)
+		} else {
+			logf(LogWarn, "Jurusan %s: %d perubahan nilai sejak run sebelumnya, lihat %s", jur.NamaJrs, len(entries), paths[len(paths)-1])
+		}
+		anomaly(AnomaliPerubahan, len(entries), "%d perubahan nilai sejak run sebelumnya", len(entries))
+	}
+	if err := collect(writeUnpublished(scraper.config, out, unpublished, scraper.config.IncludeUnpublished)); err != nil {
+		logf(LogError, "Gagal tulis daftar MK belum cetak: %v", err)
+	}
+
+	// Cek kesesuaian nil_angka dengan bobot komponen
+	siswa, kelas := checkGrades(results, scraper.config.GradeTolerance)
+	if err := collect(writeGradeCheck(scraper.config, out, siswa, kelas)); err != nil {
+		logf(LogError, "Gagal tulis laporan selisih nilai: %v", err)
+	} else if len(siswa) > 0 {
+		logf(LogWarn, "Jurusan %s: %d nilai mahasiswa tidak sesuai bobot di %d kelas", jur.NamaJrs, len(siswa), len(kelas))
+	}
+	if len(siswa) > 0 {
+		anomaly(AnomaliSelisihNilai, len(siswa), "%d nilai mahasiswa tidak sesuai bobot di %d kelas", len(siswa), len(kelas))
+	}
+
+	// Cek kesesuaian nil_huruf dengan skala nilai
+	scale := scraper.config.GradeScales.For(semester)
+	detail, sum := validateHuruf(results, scale)
+	sum.Jurusan, sum.Semester = jur.NamaJrs, semester
+	if err := collect(writeHurufCheck(scraper.config, out, detail, sum)); err != nil {
+		logf(LogError, "Gagal tulis laporan validasi huruf: %v", err)
+	} else if sum.TidakSesuai > 0 {
+		logf(LogWarn, "Jurusan %s: %d nilai huruf tidak sesuai skala %s", jur.NamaJrs, sum.TidakSesuai, scale.Nama)
+	}
+	if sum.TidakSesuai > 0 {
+		anomaly(AnomaliHuruf, sum.TidakSesuai, "%d nilai huruf tidak sesuai skala %s", sum.TidakSesuai, scale.Nama)
+	}
+
+	// Statistik nilai per kelas
+	stats := make([]ClassStats, 0, len(results))
+	for _, res := range results {
+		stats = append(stats, computeClassStats(res, scale))
+	}
+	if err := collect(writeStatistics(scraper.config, out, stats, scale)); err != nil {
+		logf(LogError, "Gagal tulis statistik nilai: %v", err)
+	}
+
+	// Laporan per dosen, termasuk kelas yang belum cetak
+	if err := collect(writeDosenReports(scraper.config, out, buildDosenReports(all, stats), scale)); err != nil {
+		logf(LogError, "Gagal tulis laporan dosen: %v", err)
+	}
+
+	// Hasil studi per mahasiswa (KHS) dan IPS, hanya dari nilai yang sudah cetak
+	published := make([]mkResult, 0, len(results))
+	for _, res := range results {
+		if res.MataKuliah.Cetak == CetakValue {
+			published = append(published, res)
+		}
+	}
+	if err := collect(writeKHS(scraper.config, out, buildKHS(published, scale, semester))); err != nil {
+		logf(LogError, "Gagal tulis KHS: %v", err)
+	}
+
+	// Kelas yang nilainya belum lengkap atau belum cetak
+	incomplete := findIncomplete(all, results)
+	if err := collect(writeIncomplete(scraper.config, out, incomplete)); err != nil {
+		logf(LogError, "Gagal tulis laporan nilai belum lengkap: %v", err)
+	} else if len(incomplete) > 0 {
+		logf(LogWarn, "Jurusan %s: %d dosen punya kelas dengan nilai belum lengkap/belum cetak", jur.NamaJrs, len(incomplete))
+	}
+	if len(incomplete) > 0 {
+		anomaly(AnomaliBelumLengkap, len(incomplete), "%d dosen punya kelas dengan nilai belum lengkap/belum cetak", len(incomplete))
+	}
+	return files
+}
+
+// mkResult is what scrapeMK fetched for a single mata kuliah
+type mkResult struct {
+	MataKuliah MataKuliah
+	Nilai      []Nilai
+	Bobot      Bobot
+	Changed    bool     // false when the content matched the manifest and nothing was written
+	Files      []string // files written for this MK
+
+	failedWrites int // output files that could not be written
+}
+
+// newMKOutput prepares the folders, manifest, audit log and (for JSONL) the
+// writer of a jurusan-semester. The returned func closes what was opened.
+func newMKOutput(scraper *Scraper, jur Jurusan, semester string) (mkOutput, func(), error) {
+	folderJSON := filepath.Join(scraper.config.JSONFolder, jur.NamaJrs, semester)
+	folderExcel := filepath.Join(scraper.config.ExcelFolder, jur.NamaJrs, semester)
+	os.MkdirAll(folderJSON, os.ModePerm)
+	os.MkdirAll(folderExcel, os.ModePerm)
+
+	manifest, err := loadContentManifest(filepath.Join(folderJSON, ContentManifestFile))
+	if err != nil {
+		return mkOutput{}, nil, err
+	}
+	audit, err := newAuditLog(folderJSON)
+	if err != nil {
+		return mkOutput{}, nil, err
+	}
+	out := mkOutput{folderJSON: folderJSON, folderExcel: folderExcel, manifest: manifest, audit: audit}
+	if scraper.config.JSONFormat == FormatJSONL {
+		jl, err := newJSONLWriter(filepath.Join(folderJSON, "nilai.jsonl"), scraper.baseURL, semester)
+		if err != nil {
+			return mkOutput{}, nil, err
+		}
+		out.jsonl = jl
+	}
+	closeOut := func() {
+		if out.jsonl != nil {
+			out.jsonl.Close()
+		}
+	}
+	return out, closeOut, nil
+}
+
+// mkOutput holds the destinations scrapeMK writes a mata kuliah to
+type mkOutput struct {
+	folderJSON  string
+	folderExcel string
+	jsonl       *jsonlWriter // nil unless JSON_FORMAT=jsonl
+	manifest    *ContentManifest
+	audit       *auditLog
+}
+
+// attemptMK runs scrapeMK and describes the outcome for the run manifest
+func attemptMK(scraper *Scraper, jur Jurusan, semester string, mk MataKuliah, out mkOutput) (*mkResult, MKStatus, error) {
+	start := time.Now()
+	res, err := scrapeMK(scraper, mk, out)
+	st := MKStatus{
+		Jurusan:    jur,
+		Semester:   semester,
+		MataKuliah: mk,
+		Status:     StatusOK,
+		Berubah:    res.Changed,
+		Rows:       len(res.Nilai),
+		Files:      res.Files,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		st.Status, st.Error = StatusFailed, err.Error()
+	}
+	return res, st, err
+}
+
+// scrapeMK fetches and writes nilai and bobot of mk. The result is never nil,
+// also when err is returned.
+func scrapeMK(scraper *Scraper, mk MataKuliah, out mkOutput) (*mkResult, error) {
+	res := &mkResult{MataKuliah: mk}
 	nilai, err := scraper.GetListNilai(mk.Infomk)
 	if err != nil {
+		metricMK.Inc("failed")
 		logf(LogError, "Gagal ambil nilai MK %s: %v", mk.Namamk, err)
-		return
+		return res, err
 	}
+	metricMK.Inc("ok")
 	infomk := strings.Split(mk.Infomk, "#")
 	fak := infomk[0]
 	// Get bobot data
@@ -86,28 +293,95 @@ func scrapeMK(scraper *Scraper, mk MataKuliah, folderJSON, folderExcel string) {
 		bobotData = Bobot{}
 	}
 
-	namaFile := sanitizeFilename(fmt.Sprintf("%s R%s %s", mk.Namamk, mk.Kelas, mk.Namadosen))
+	res.Nilai, res.Bobot = nilai, bobotData
+	namaFile := sanitizeFilename(fmt.Sprintf("%s R%s %s%s", mk.Namamk, mk.Kelas, mk.Namadosen, unpublishedSuffix(mk)))
+	namaFileBobot := sanitizeFilename(fmt.Sprintf("%s R%s %s%s_bobot", mk.Namamk, mk.Kelas, mk.Namadosen, unpublishedSuffix(mk)))
+	ext := sheetExt(scraper.config.SheetFormat)
+	jsonNilai := filepath.Join(out.folderJSON, namaFile+".json")
+	jsonBobot := filepath.Join(out.folderJSON, namaFileBobot+".json")
+	excelNilai := filepath.Join(out.folderExcel, namaFile+ext)
+	excelBobot := filepath.Join(out.folderExcel, namaFileBobot+ext)
+
+	// file yang harus ada untuk format output sekarang; ganti format = tulis ulang
+	expected := []string{excelNilai, excelBobot}
+	if out.jsonl != nil {
+		expected = append(expected, out.jsonl.Path())
+	} else {
+		expected = append(expected, jsonNilai, jsonBobot)
+	}
 
-	// Write nilai data
-	if err := writeJSON(filepath.Join(folderJSON, namaFile+".json"), nilai); err != nil {
-		logf(LogError, "Gagal tulis JSON nilai: %v", err)
+	hash := contentHash(mk, nilai, bobotData)
+	if out.manifest != nil && out.manifest.Unchanged(mk, hash, expected) {
+		if out.audit != nil {
+			if err := out.audit.Baseline(mk, nilai); err != nil {
+				logf(LogWarn, "Gagal simpan snapshot nilai MK %s: %v", mk.Namamk, err)
+			}
+		}
+		return res, nil
 	}
-	if err := writeExcel(filepath.Join(folderExcel, namaFile+".xlsx"), nilai, mk); err != nil {
-		logf(LogError, "Gagal tulis Excel nilai: %v", err)
+	res.Changed = true
+
+	if out.audit != nil {
+		if err := out.audit.Record(mk, nilai); err != nil {
+			logf(LogWarn, "Gagal audit nilai MK %s: %v", mk.Namamk, err)
+		}
 	}
 
-	// Write bobot data
 	bobotMK := BobotMK{
 		MataKuliah: mk,
 		Bobot:      bobotData,
 	}
-	namaFileBobot := sanitizeFilename(fmt.Sprintf("%s R%s %s_bobot", mk.Namamk, mk.Kelas, mk.Namadosen))
-	if err := writeJSON(filepath.Join(folderJSON, namaFileBobot+".json"), bobotMK); err != nil {
-		logf(LogError, "Gagal tulis JSON bobot: %v", err)
+
+	// Write nilai & bobot JSON
+	if out.jsonl != nil {
+		records := make([]interface{}, len(nilai))
+		for i, n := range nilai {
+			records[i] = n
+		}
+		if err := out.jsonl.WriteMK(KindNilai, mk, records...); err != nil {
+			logf(LogError, "Gagal tulis JSONL nilai: %v", err)
+			res.failedWrites++
+		} else if err := out.jsonl.WriteMK(KindBobot, mk, bobotMK); err != nil {
+			logf(LogError, "Gagal tulis JSONL bobot: %v", err)
+			res.failedWrites++
+		} else {
+			res.Files = append(res.Files, out.jsonl.Path())
+		}
+	} else {
+		res.write("JSON nilai", jsonNilai, func(path string) error {
+			return writeJSON(path, nilai)
+		})
+		res.write("JSON bobot", jsonBobot, func(path string) error {
+			return writeJSON(path, bobotMK)
+		})
+	}
+
+	// Write nilai & bobot Excel
+	res.write("Excel nilai", excelNilai, func(path string) error {
+		return writeExcel(path, scraper.config.ExcelHeaders, nilai, mk)
+	})
+	res.write("Excel bobot", excelBobot, func(path string) error {
+		return writeBobotExcel(path, bobotMK)
+	})
+
+	// hanya dicatat jika semua file tertulis, agar yang gagal ditulis ulang di run berikutnya
+	if out.manifest != nil && res.failedWrites == 0 {
+		out.manifest.Update(mk, hash, res.Files)
+	}
+	return res, nil
+}
+
+// write runs fn for path, logging failures and remembering the path on success
+func (res *mkResult) write(label, path string, fn func(path string) error) {
+	if err := fn(path); err != nil {
+		logf(LogError, "Gagal tulis %s: %v", label, err)
+		res.failedWrites++
+		return
 	}
-	if err := writeBobotExcel(filepath.Join(folderExcel, namaFileBobot+".xlsx"), bobotMK); err != nil {
-		logf(LogError, "Gagal tulis Excel bobot: %v", err)
+	if info, err := os.Stat(path); err == nil {
+		metricBytes.Add(float64(info.Size()))
 	}
+	res.Files = append(res.Files, path)
 }
 
 func writeJSON(path string, data interface{}) error {
@@ -118,65 +392,56 @@ func writeJSON(path string, data interface{}) error {
 	return enc.Encode(data)
 }
 
-func writeExcel(path string, data []Nilai, mk MataKuliah) error {
-	f := excelize.NewFile()
-	sheet := "Sheet1"
-	headers := []string{ExcelNIM, ExcelNama, ExcelKodeMK, ExcelNamaMK, ExcelSemester, ExcelKelas, ExcelAngka, ExcelHuruf, ExcelKehadiran, ExcelProjek, ExcelQuiz, ExcelTugas, ExcelUTS, ExcelUAS, ExcelKodePK, ExcelNamaPK, ExcelKodeProdi, ExcelNamaProdi}
-
-	for i, h := range headers {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheet, cell, h)
-	}
-
-	for i, n := range data {
-		row := i + 2
-		vals := []interface{}{n.NIM, n.Nama, mk.KodeMK, mk.Namamk, mk.Smtthnakd, mk.Kelas, n.NilAngka, n.NilHuruf, n.Hadir, n.Projek, n.Quiz, n.Tugas, n.UTS, n.UAS, mk.KodeJrs, mk.NamaJrs, mk.KodeJrs, mk.NamaJrs}
-		for j, v := range vals {
-			cell, _ := excelize.CoordinatesToCellName(j+1, row)
-			f.SetCellValue(sheet, cell, v)
-		}
+// unpublishedSuffix marks output file names of MK whose grades are not published yet
+func unpublishedSuffix(mk MataKuliah) string {
+	if mk.Cetak != CetakValue {
+		return " (Belum Cetak)"
 	}
-	return f.SaveAs(path)
+	return ""
 }
 
-func writeBobotExcel(path string, data BobotMK) error {
-	f := excelize.NewFile()
-	sheet := "Sheet1"
-
-	// Set mata kuliah information headers
-	headers := []string{"Mata Kuliah", "Kelas", "Dosen", "Kode MK", "Kode Prodi", "Kode PK"}
-	for i, h := range headers {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheet, cell, h)
+// writeUnpublished writes the list of MK with cetak != 1 as "MK Belum Cetak" JSON and spreadsheet
+func writeUnpublished(cfg *Config, out mkOutput, list []MataKuliah, scraped bool) ([]string, error) {
+	rows := [][]interface{}{{"Kode MK", "Nama MK", "Kelas", "Dosen", "Cetak", "Diambil"}}
+	for _, mk := range list {
+		rows = append(rows, []interface{}{mk.KodeMK, mk.Namamk, mk.Kelas, mk.Namadosen, mk.Cetak, scraped})
 	}
-
-	// Set mata kuliah information values
-	mkVals := []interface{}{data.MataKuliah.Namamk, data.MataKuliah.Kelas, data.MataKuliah.Namadosen, data.MataKuliah.KodeMK, data.MataKuliah.KodeJrs, data.MataKuliah.KodePK}
-	for i, v := range mkVals {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 2)
-		f.SetCellValue(sheet, cell, v)
+	if list == nil {
+		list = []MataKuliah{}
 	}
+	return writeReport(cfg, out, "MK Belum Cetak", list, sheetTable{Name: "MK Belum Cetak", Rows: rows})
+}
 
-	// Add bobot headers starting from row 4
-	f.SetCellValue(sheet, "A4", "Bobot (%)")
-
-	// Add bobot data starting from row 5
-	bobotComponents := []string{"Hadir", "Projek", "Quiz", "Tugas", "UTS", "UAS"}
-	bobotValues := []string{data.Bobot.Hadir, data.Bobot.Projek, data.Bobot.Quiz, data.Bobot.Tugas, data.Bobot.UTS, data.Bobot.UAS}
-	for i, component := range bobotComponents {
-
-		// Set component name in column A
-		cell, _ := excelize.CoordinatesToCellName(i+1, 5)
-		f.SetCellValue(sheet, cell, component)
-		// Set bobot value in column B
+func nilaiTable(headers ExcelHeaders, data []Nilai, mk MataKuliah) sheetTable {
+	rows := [][]interface{}{headerRow(headers.Nilai())}
+	for _, n := range data {
+		rows = append(rows, []interface{}{n.NIM, n.Nama, mk.KodeMK, mk.Namamk, mk.Smtthnakd, mk.Kelas, n.NilAngka, n.NilHuruf, n.Hadir, n.Projek, n.Quiz, n.Tugas, n.UTS, n.UAS, mk.KodeJrs, mk.NamaJrs, mk.KodeJrs, mk.NamaJrs})
 	}
-	for i, value := range bobotValues {
+	return sheetTable{Name: "Sheet1", Rows: rows}
+}
 
-		cell, _ := excelize.CoordinatesToCellName(i+1, 6)
-		f.SetCellValue(sheet, cell, value)
+func bobotTable(data BobotMK) sheetTable {
+	rows := [][]interface{}{
+		// Mata kuliah information
+		{"Mata Kuliah", "Kelas", "Dosen", "Kode MK", "Kode Prodi", "Kode PK"},
+		{data.MataKuliah.Namamk, data.MataKuliah.Kelas, data.MataKuliah.Namadosen, data.MataKuliah.KodeMK, data.MataKuliah.KodeJrs, data.MataKuliah.KodePK},
+		{},
+		// Bobot components and values
+		{"Bobot (%)"},
+		{"Hadir", "Projek", "Quiz", "Tugas", "UTS", "UAS"},
+		{data.Bobot.Hadir, data.Bobot.Projek, data.Bobot.Quiz, data.Bobot.Tugas, data.Bobot.UTS, data.Bobot.UAS},
 	}
+	return sheetTable{Name: "Sheet1", Rows: rows}
+}
 
-	return f.SaveAs(path)
+// writeExcel writes the nilai sheet as .xlsx or .ods depending on the path extension
+func writeExcel(path string, headers ExcelHeaders, data []Nilai, mk MataKuliah) error {
+	return writeTables(path, nilaiTable(headers, data, mk))
+}
+
+// writeBobotExcel writes the bobot sheet as .xlsx or .ods depending on the path extension
+func writeBobotExcel(path string, data BobotMK) error {
+	return writeTables(path, bobotTable(data))
 }
 
 // no changes
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+ <manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+const odsStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" office:version="1.2"/>
+`
+
+const odsContentHead = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+<office:body><office:spreadsheet>
+`
+
+const odsContentTail = `</office:spreadsheet></office:body></office:document-content>
+`
+
+// writeODS writes the tables as an OpenDocument spreadsheet
+func writeODS(path string, tables []sheetTable) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	zw := zip.NewWriter(file)
+	now := time.Now()
+
+	// mimetype harus entry pertama dan tidak dikompresi
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: now})
+	if err != nil {
+		return err
+	}
+	if _, err := mw.Write([]byte(odsMimeType)); err != nil {
+		return err
+	}
+
+	for _, part := range []struct{ name, content string }{
+		{"META-INF/manifest.xml", odsManifest},
+		{"styles.xml", odsStyles},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+
+	cw, err := zw.CreateHeader(&zip.FileHeader{Name: "content.xml", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	if err := writeODSContent(cw, tables); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeODSContent(w io.Writer, tables []sheetTable) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(odsContentHead)
+	for _, t := range tables {
+		fmt.Fprintf(bw, `<table:table table:name="%s">`, odsEscape(t.Name))
+		for _, row := range t.Rows {
+			bw.WriteString("<table:table-row>")
+			if len(row) == 0 {
+				bw.WriteString("<table:table-cell/>")
+			}
+			for _, v := range row {
+				writeODSCell(bw, v)
+			}
+			bw.WriteString("</table:table-row>\n")
+		}
+		bw.WriteString("</table:table>\n")
+	}
+	bw.WriteString(odsContentTail)
+	return bw.Flush()
+}
+
+func writeODSCell(bw *bufio.Writer, v interface{}) {
+	var num string
+	switch n := v.(type) {
+	case nil:
+		bw.WriteString("<table:table-cell/>")
+		return
+	case int:
+		num = strconv.Itoa(n)
+	case int64:
+		num = strconv.FormatInt(n, 10)
+	case float64:
+		num = strconv.FormatFloat(n, 'f', -1, 64)
+	case bool:
+		fmt.Fprintf(bw, `<table:table-cell office:value-type="boolean" office:boolean-value="%t"><text:p>%t</text:p></table:table-cell>`, n, n)
+		return
+	}
+	if num != "" {
+		fmt.Fprintf(bw, `<table:table-cell office:value-type="float" office:value="%s"><text:p>%s</text:p></table:table-cell>`, num, num)
+		return
+	}
+	s := fmt.Sprint(v)
+	if s == "" {
+		bw.WriteString("<table:table-cell/>")
+		return
+	}
+	fmt.Fprintf(bw, `<table:table-cell office:value-type="string"><text:p>%s</text:p></table:table-cell>`, odsEscape(s))
+}
+
+func odsEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileName is the profile given with --profile
+var profileName string
+
+// Profile is one institution and account, chosen with --profile or
+// SIAKAD_PROFILE. It sits between the config file and the environment, so
+// env such as OUTPUT_ROOT or COOKIE_FILE still override it. Its base_url is
+// the exception: it wins over BASE_URL so the profile's credentials are never
+// sent to another host. Empty fields keep
+// the top-level settings (USER_SIAKAD/PASSWORD_SIAKAD for the credentials),
+// except the output root and session store: they default to a folder named
+// after the profile so two profiles never share a session or overwrite each
+// other's files.
+type Profile struct {
+	BaseURL    string `yaml:"base_url"`
+	Deployment string `yaml:"deployment"`
+	RegValue   string `yaml:"reg_value"`
+	Username   string `yaml:"username"` // USER_SIAKAD_<PROFIL> menimpa
+	Password   string `yaml:"password"` // PASSWORD_SIAKAD_<PROFIL> menimpa
+	Cookie     string `yaml:"cookie"`
+	Jurusan    string `yaml:"jurusan"`
+	OutputRoot string `yaml:"output_root"`
+}
+
+// selectedProfile returns the profile name from --profile or SIAKAD_PROFILE
+func selectedProfile() string {
+	if profileName != "" {
+		return profileName
+	}
+	return os.Getenv("SIAKAD_PROFILE")
+}
+
+// profileEnvSuffix turns a profile name into the suffix of its credential
+// variables, e.g. kampus-a -> KAMPUS_A
+func profileEnvSuffix(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// applyProfile puts the profile called name over f and returns it with its
+// credentials resolved. Call it before applyEnv, and profileCookie after.
+func (f *fileConfig) applyProfile(name string) (Profile, error) {
+	p, ok := f.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(f.Profiles))
+		for n := range f.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return Profile{}, fmt.Errorf("profil %s tidak ditemukan, belum ada profiles di file konfigurasi", name)
+		}
+		return Profile{}, fmt.Errorf("profil %s tidak ditemukan (tersedia: %s)", name, strings.Join(names, ", "))
+	}
+
+	suffix := profileEnvSuffix(name)
+	envString(&p.Username, "USER_SIAKAD_"+suffix)
+	envString(&p.Password, "PASSWORD_SIAKAD_"+suffix)
+	if p.Username == "" {
+		p.Username = os.Getenv("USER_SIAKAD")
+	}
+	if p.Password == "" {
+		p.Password = os.Getenv("PASSWORD_SIAKAD")
+	}
+	if p.OutputRoot == "" {
+		p.OutputRoot = name
+	}
+
+	if p.BaseURL != "" {
+		f.SIAKAD.BaseURL = p.BaseURL
+	}
+	if p.Deployment != "" {
+		f.SIAKAD.Deployment = p.Deployment
+	}
+	if p.RegValue != "" {
+		f.SIAKAD.RegValue = p.RegValue
+	}
+	if p.Jurusan != "" {
+		f.Files.Jurusan = p.Jurusan
+	}
+	if p.Cookie != "" {
+		f.Files.Cookie = p.Cookie
+	}
+	f.Output.Root = p.OutputRoot
+	return p, nil
+}
+
+// profileCookie keeps the session of profile p under the output root unless
+// profiles.<name>.cookie or COOKIE_FILE names the file
+func (f *fileConfig) profileCookie(p Profile) {
+	if p.Cookie == "" && os.Getenv("COOKIE_FILE") == "" {
+		f.Files.Cookie = underRoot(f.Output.Root, filepath.Base(f.Files.Cookie))
+	}
+}
+
+// underRoot places a relative path under the output root
+func underRoot(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ProgressRedrawInterval limits how often the bars are redrawn on a TTY
+	ProgressRedrawInterval = 100 * time.Millisecond
+	// ProgressLogInterval is how often a plain progress line is logged when
+	// stdout is not a terminal
+	ProgressLogInterval = 5 * time.Second
+)
+
+// terminal is the shared stdout writer. Everything written through it while
+// bars are shown lands above the bars, which are then redrawn.
+var terminal = newProgress(os.Stdout)
+
+// Progress draws one bar per running jurusan plus an overall bar. On a
+// non-TTY it logs a plain progress line every ProgressLogInterval instead.
+type Progress struct {
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	bars    []*Bar
+	overall *Bar
+	planned int // jurusan expected in this batch, see Plan
+	ended   int // bars finished in this batch
+	drawn   int // bar lines currently on screen
+	drawnAt time.Time
+}
+
+// Bar tracks the MK of a single jurusan
+type Bar struct {
+	p        *Progress
+	label    string
+	done     int
+	total    int
+	start    time.Time
+	loggedAt time.Time
+}
+
+func newProgress(f *os.File) *Progress {
+	info, err := f.Stat()
+	tty := err == nil && info.Mode()&os.ModeCharDevice != 0
+	return &Progress{out: f, tty: tty}
+}
+
+// Write prints b above the bars
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.out.Write(b)
+	p.draw()
+	return n, err
+}
+
+// Plan announces how many jurusan the next bars belong to, so the overall
+// bar stays up between them
+func (p *Progress) Plan(jurusan int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.planned, p.ended = jurusan, 0
+	if len(p.bars) == 0 {
+		p.overall = nil
+	}
+}
+
+// Begin starts a bar for total MK
+func (p *Progress) Begin(label string, total int) *Bar {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if p.overall == nil {
+		p.overall = &Bar{p: p, label: "Total", start: now, loggedAt: now}
+	}
+	p.overall.total += total
+	b := &Bar{p: p, label: label, total: total, start: now, loggedAt: now}
+	p.bars = append(p.bars, b)
+	p.clear()
+	p.draw()
+	return b
+}
+
+// Inc marks one more MK of the bar as done
+func (b *Bar) Inc() {
+	p := b.p
+	p.mu.Lock()
+	b.done++
+	p.overall.done++
+	var line string
+	if p.tty {
+		if b.done == b.total || time.Since(p.drawnAt) >= ProgressRedrawInterval {
+			p.clear()
+			p.draw()
+		}
+	} else if time.Since(b.loggedAt) >= ProgressLogInterval && b.done < b.total {
+		b.loggedAt = time.Now()
+		line = b.line(false)
+	}
+	p.mu.Unlock()
+
+	if line != "" {
+		slog.Info("Progress " + line)
+	}
+}
+
+// Done removes the bar and leaves its final state as a normal line
+func (b *Bar) Done() {
+	p := b.p
+	p.mu.Lock()
+	for i, other := range p.bars {
+		if other == b {
+			p.bars = append(p.bars[:i], p.bars[i+1:]...)
+			break
+		}
+	}
+	p.ended++
+	lines := []string{b.line(false) + " selesai dalam " + formatDuration(time.Since(b.start))}
+	if p.planned > 1 {
+		lines = append(lines, p.overall.line(false))
+	}
+	if len(p.bars) == 0 && p.ended >= p.planned {
+		p.overall, p.planned, p.ended = nil, 0, 0
+	}
+	p.mu.Unlock()
+
+	for _, line := range lines {
+		slog.Info("Progress " + line)
+	}
+}
+
+// clear erases the bars drawn last; the caller holds p.mu
+func (p *Progress) clear() {
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\033[%dA\r\033[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+// draw prints the bars below the cursor; the caller holds p.mu
+func (p *Progress) draw() {
+	if !p.tty || len(p.bars) == 0 {
+		return
+	}
+	var b strings.Builder
+	for _, bar := range p.bars {
+		b.WriteString(bar.line(true) + "\n")
+	}
+	lines := len(p.bars)
+	if len(p.bars) > 1 || p.planned > 1 {
+		b.WriteString(p.overall.line(true) + "\n")
+		lines++
+	}
+	io.WriteString(p.out, b.String())
+	p.drawn = lines
+	p.drawnAt = time.Now()
+}
+
+// line renders the bar with percentage, throughput and ETA
+func (b *Bar) line(graphic bool) string {
+	percent := 100
+	if b.total > 0 {
+		percent = b.done * 100 / b.total
+	}
+	s := fmt.Sprintf("%s: %d%% (%d/%d)", b.label, percent, b.done, b.total)
+	if graphic {
+		pos := percent * ProgressBarLength / 100
+		bar := strings.Repeat("█", pos) + strings.Repeat(" ", ProgressBarLength-pos)
+		s = fmt.Sprintf("[PROGRESS] %s: [%s] %d%% (%d/%d)", b.label, bar, percent, b.done, b.total)
+	}
+	elapsed := time.Since(b.start)
+	if b.done > 0 && elapsed > 0 {
+		rate := float64(b.done) / elapsed.Seconds()
+		s += fmt.Sprintf(" %.1f MK/s", rate)
+		if b.done < b.total {
+			eta := time.Duration(float64(b.total-b.done) / rate * float64(time.Second))
+			s += " ETA " + formatDuration(eta)
+		}
+	}
+	return s
+}
+
+// BarState is a point-in-time copy of a bar
+type BarState struct {
+	Label string `json:"label"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// Snapshot returns the running bars, followed by the overall bar if any
+func (p *Progress) Snapshot() []BarState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var states []BarState
+	for _, b := range p.bars {
+		states = append(states, BarState{Label: b.label, Done: b.done, Total: b.total})
+	}
+	if p.overall != nil {
+		states = append(states, BarState{Label: p.overall.label, Done: p.overall.done, Total: p.overall.total})
+	}
+	return states
+}
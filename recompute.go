@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GradeDiscrepancy is a student whose nil_angka differs from the weighted components
+type GradeDiscrepancy struct {
+	NIM       string  `json:"nim"`
+	Nama      string  `json:"nama"`
+	KodeMK    string  `json:"kodemk"`
+	Namamk    string  `json:"namamk"`
+	Kelas     string  `json:"kelas"`
+	Namadosen string  `json:"namadosen"`
+	NilAngka  string  `json:"nil_angka"`
+	Hitung    float64 `json:"hitung"`
+	Selisih   float64 `json:"selisih"`
+}
+
+// ClassDiscrepancy summarizes the recomputation check of one class
+type ClassDiscrepancy struct {
+	KodeMK      string  `json:"kodemk"`
+	Namamk      string  `json:"namamk"`
+	Kelas       string  `json:"kelas"`
+	Namadosen   string  `json:"namadosen"`
+	Mahasiswa   int     `json:"mahasiswa"`
+	TidakSesuai int     `json:"tidak_sesuai"`
+	MaxSelisih  float64 `json:"max_selisih"`
+	Keterangan  string  `json:"keterangan,omitempty"`
+}
+
+// GradeCheckReport is the JSON layout of the recomputation report
+type GradeCheckReport struct {
+	Toleransi float64            `json:"toleransi"`
+	Kelas     []ClassDiscrepancy `json:"kelas"`
+	Mahasiswa []GradeDiscrepancy `json:"mahasiswa"`
+}
+
+// parseScore parses a numeric field from SIAKAD, accepting a comma as decimal separator
+func parseScore(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", "."))
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// recomputeNilai returns Σ komponen × bobot / 100. Empty components count as 0.
+// ok is false when the bobot is empty or not numeric.
+func recomputeNilai(n Nilai, b Bobot) (float64, bool) {
+	komponen := []string{n.Hadir, n.Projek, n.Quiz, n.Tugas, n.UTS, n.UAS}
+	bobot := []string{b.Hadir, b.Projek, b.Quiz, b.Tugas, b.UTS, b.UAS}
+
+	var total, totalBobot float64
+	for i := range bobot {
+		w, ok := parseScore(bobot[i])
+		if !ok {
+			w = 0
+		}
+		v, _ := parseScore(komponen[i])
+		total += v * w / 100
+		totalBobot += w
+	}
+	if totalBobot == 0 {
+		return 0, false
+	}
+	return total, true
+}
+
+// checkGrades compares every nil_angka against its recomputed value. Only classes
+// with a discrepancy or without usable bobot are listed in the class summary.
+func checkGrades(results []mkResult, tolerance float64) ([]GradeDiscrepancy, []ClassDiscrepancy) {
+	var siswa []GradeDiscrepancy
+	var kelas []ClassDiscrepancy
+	for _, res := range results {
+		mk := res.MataKuliah
+		cls := ClassDiscrepancy{
+			KodeMK:    mk.KodeMK,
+			Namamk:    mk.Namamk,
+			Kelas:     mk.Kelas,
+			Namadosen: mk.Namadosen,
+			Mahasiswa: len(res.Nilai),
+		}
+		for _, n := range res.Nilai {
+			hitung, ok := recomputeNilai(n, res.Bobot)
+			if !ok {
+				cls.Keterangan = "bobot kosong"
+				break
+			}
+			angka, ok := parseScore(n.NilAngka)
+			if !ok {
+				// nilai belum diisi, dilaporkan oleh pengecekan lain
+				continue
+			}
+			selisih := math.Round((angka-hitung)*100) / 100
+			if math.Abs(selisih) <= tolerance {
+				continue
+			}
+			cls.TidakSesuai++
+			cls.MaxSelisih = math.Max(cls.MaxSelisih, math.Abs(selisih))
+			siswa = append(siswa, GradeDiscrepancy{
+				NIM:       n.NIM,
+				Nama:      n.Nama,
+				KodeMK:    mk.KodeMK,
+				Namamk:    mk.Namamk,
+				Kelas:     mk.Kelas,
+				Namadosen: mk.Namadosen,
+				NilAngka:  n.NilAngka,
+				Hitung:    math.Round(hitung*100) / 100,
+				Selisih:   selisih,
+			})
+		}
+		if cls.TidakSesuai > 0 || cls.Keterangan != "" {
+			kelas = append(kelas, cls)
+		}
+	}
+	return siswa, kelas
+}
+
+// writeGradeCheck writes the discrepancy report as "Selisih Nilai" JSON and spreadsheet
+func writeGradeCheck(cfg *Config, out mkOutput, siswa []GradeDiscrepancy, kelas []ClassDiscrepancy) ([]string, error) {
+	kelasRows := [][]interface{}{{"Kode MK", "Nama MK", "Kelas", "Dosen", "Jumlah Mahasiswa", "Tidak Sesuai", "Selisih Maks", "Keterangan"}}
+	for _, k := range kelas {
+		kelasRows = append(kelasRows, []interface{}{k.KodeMK, k.Namamk, k.Kelas, k.Namadosen, k.Mahasiswa, k.TidakSesuai, k.MaxSelisih, k.Keterangan})
+	}
+	siswaRows := [][]interface{}{{"NIM", "Nama", "Kode MK", "Nama MK", "Kelas", "Dosen", "Nilai Angka", "Nilai Hitung", "Selisih"}}
+	for _, s := range siswa {
+		siswaRows = append(siswaRows, []interface{}{s.NIM, s.Nama, s.KodeMK, s.Namamk, s.Kelas, s.Namadosen, s.NilAngka, s.Hitung, s.Selisih})
+	}
+
+	report := GradeCheckReport{Toleransi: cfg.GradeTolerance, Kelas: kelas, Mahasiswa: siswa}
+	return writeReport(cfg, out, "Selisih Nilai", report,
+		sheetTable{Name: "Per Kelas", Rows: kelasRows},
+		sheetTable{Name: "Per Mahasiswa", Rows: siswaRows},
+	)
+}
@@ -0,0 +1,17 @@
+package main
+
+import "path/filepath"
+
+// writeReport writes a jurusan-semester report as JSON next to the nilai
+// files and as a spreadsheet next to the Excel files. It returns the paths written.
+func writeReport(cfg *Config, out mkOutput, name string, data interface{}, tables ...sheetTable) ([]string, error) {
+	jsonPath := filepath.Join(out.folderJSON, name+".json")
+	if err := writeJSON(jsonPath, data); err != nil {
+		return nil, err
+	}
+	sheetPath := filepath.Join(out.folderExcel, name+sheetExt(cfg.SheetFormat))
+	if err := writeTables(sheetPath, tables...); err != nil {
+		return []string{jsonPath}, err
+	}
+	return []string{jsonPath, sheetPath}, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// runRetry re-scrapes only the failed MK of a previous run and merges the
+// outcome back into that run's manifest
+func runRetry(args []string) error {
+	fs := flag.NewFlagSet("retry", flag.ExitOnError)
+	manifestPath := fs.String("manifest", "", "path manifest run (kosong = manifest terbaru di "+RunFolder+")")
+	fs.Parse(args)
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("gagal load konfigurasi: %w", err)
+	}
+	path := *manifestPath
+	if path == "" {
+		latest, err := latestRunManifest(config.RunFolder)
+		if err != nil {
+			return err
+		}
+		path = latest
+	}
+	run, err := loadRun(path)
+	if err != nil {
+		return err
+	}
+
+	failed := run.Failed()
+	if len(failed) == 0 {
+		logf(LogInfo, "Tidak ada MK gagal di %s", path)
+		return nil
+	}
+	logf(LogInfo, "Retry %d MK gagal dari %s", len(failed), path)
+	scraper := NewScraper(config)
+	if err := handleAuthentication(scraper); err != nil {
+		return fmt.Errorf("gagal autentikasi: %w", err)
+	}
+
+	run.MarkRetried(time.Now())
+	err = retryFailed(scraper, run, failed)
+	finishRun(config, run, err)
+	return err
+}
+
+// retryGroup is the failed MK of one jurusan-semester, which share a SetProdi
+type retryGroup struct {
+	jur      Jurusan
+	semester string
+	idx      []int
+}
+
+// retryFailed re-applies SetProdi per jurusan-semester and re-scrapes the failed MK
+func retryFailed(scraper *Scraper, run *Run, failed map[int]MKStatus) error {
+	groups := map[string]*retryGroup{}
+	defer terminal.Plan(0)
+	var keys []string
+	for i, st := range failed {
+		key := st.Jurusan.KodeJrs + "|" + st.Semester
+		g, ok := groups[key]
+		if !ok {
+			g = &retryGroup{jur: st.Jurusan, semester: st.Semester}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.idx = append(g.idx, i)
+	}
+	sort.Strings(keys)
+	terminal.Plan(len(keys))
+
+	for _, key := range keys {
+		g := groups[key]
+		sort.Ints(g.idx)
+		if err := scraper.SetProdi(g.jur.KodeJrs, scraper.config.RegValue, g.semester); err != nil {
+			notifySetProdiFailed(scraper.config, g.jur, g.semester, err)
+			return fmt.Errorf("gagal set prodi untuk jurusan %s: %w", g.jur.NamaJrs, err)
+		}
+		out, closeOut, err := newMKOutput(scraper, g.jur, g.semester)
+		if err != nil {
+			return err
+		}
+
+		ok := 0
+		bar := terminal.Begin(g.jur.NamaJrs, len(g.idx))
+		for _, i := range g.idx {
+			old := failed[i]
+			_, st, err := attemptMK(scraper, g.jur, g.semester, old.MataKuliah, out)
+			st.Retries = old.Retries + 1
+			metricRetries.Inc()
+			run.UpdateMK(i, st)
+			if err == nil {
+				ok++
+			}
+			bar.Inc()
+		}
+		bar.Done()
+
+		if err := out.manifest.Save(); err != nil {
+			logf(LogError, "Gagal simpan manifest: %v", err)
+		}
+		closeOut()
+		logf(LogInfo, "Jurusan %s semester %s: %d dari %d MK berhasil di-retry", g.jur.NamaJrs, g.semester, ok, len(g.idx))
+	}
+	logf(LogInfo, "Laporan jurusan tidak dibuat ulang oleh retry; jalankan scraping jurusan untuk laporan lengkap")
+	return nil
+}
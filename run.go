@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunFolder holds one manifest per run, under the output root
+const RunFolder = "runs"
+
+// Run modes
+const (
+	ModeNilai     = "nilai"
+	ModeMahasiswa = "mahasiswa"
+	ModeKeduanya  = "keduanya"
+)
+
+// MK statuses in a run manifest
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// MKStatus is the outcome of one attempted mata kuliah
+type MKStatus struct {
+	Jurusan    Jurusan    `json:"jurusan"`
+	Semester   string     `json:"semester"`
+	MataKuliah MataKuliah `json:"mata_kuliah"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Berubah    bool       `json:"berubah"`
+	Rows       int        `json:"rows"`
+	Files      []string   `json:"files,omitempty"`
+	Retries    int        `json:"retries"` // berapa kali di-retry dengan perintah retry
+	DurationMS int64      `json:"duration_ms"`
+}
+
+// Anomaly kinds found by the jurusan reports
+const (
+	AnomaliPerubahan    = "perubahan_nilai"
+	AnomaliSelisihNilai = "selisih_nilai"
+	AnomaliHuruf        = "huruf_tidak_sesuai"
+	AnomaliBelumLengkap = "nilai_belum_lengkap"
+)
+
+// Anomaly counts one kind of grade problem in a jurusan-semester
+type Anomaly struct {
+	Jurusan    Jurusan `json:"jurusan"`
+	Semester   string  `json:"semester"`
+	Jenis      string  `json:"jenis"`
+	Jumlah     int     `json:"jumlah"`
+	Keterangan string  `json:"keterangan"`
+}
+
+// RunSummary counts the MK statuses of a run
+type RunSummary struct {
+	OK      int `json:"ok"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// RunManifest is the machine-readable record of a run, stored in RunFolder
+type RunManifest struct {
+	ID        string      `json:"id"`
+	User      string      `json:"user"`
+	Profile   string      `json:"profile,omitempty"`
+	Mode      string      `json:"mode"`
+	Semester  string      `json:"semester"`
+	Jurusan   []Jurusan   `json:"jurusan"`
+	Start     time.Time   `json:"start"`
+	End       time.Time   `json:"end"`
+	Status    string      `json:"status"`
+	Error     string      `json:"error,omitempty"`
+	Summary   RunSummary  `json:"summary"`
+	Retried   []time.Time `json:"retried,omitempty"` // waktu mode retry dijalankan
+	Files     []string    `json:"files,omitempty"`   // file di luar MK (laporan, data mahasiswa)
+	Anomalies []Anomaly   `json:"anomalies,omitempty"`
+	MK        []MKStatus  `json:"mk"`
+}
+
+// Run collects the manifest of a run while it is in progress.
+// Safe for use from multiple goroutines.
+type Run struct {
+	mu       sync.Mutex
+	dir      string // folder manifest, config.RunFolder
+	path     string // kosong = dir/<ID>.json
+	loaded   bool   // dimuat dari manifest oleh retry
+	metrics  metricsSnapshot
+	Manifest RunManifest
+}
+
+func newRun(config *Config) *Run {
+	now := time.Now()
+	id := now.Format("20060102-150405")
+	// run non-interaktif bisa mulai di detik yang sama dengan run sebelumnya
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(config.RunFolder, id+".json")); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+	return &Run{dir: config.RunFolder, metrics: takeMetricsSnapshot(), Manifest: RunManifest{
+		ID:      id,
+		User:    config.Username,
+		Profile: config.Profile,
+		Start:   now,
+		MK:      []MKStatus{},
+	}}
+}
+
+func (r *Run) SetSemester(semester string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.Semester = semester
+}
+
+func (r *Run) SetMode(mode string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.Mode = mode
+}
+
+func (r *Run) AddJurusan(jur Jurusan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.Manifest.Jurusan {
+		if j.KodeJrs == jur.KodeJrs {
+			return
+		}
+	}
+	r.Manifest.Jurusan = append(r.Manifest.Jurusan, jur)
+}
+
+// loadRun reads a run manifest so it can be updated and saved back to path
+func loadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal baca manifest %s: %w", path, err)
+	}
+	r := &Run{path: path, loaded: true}
+	if err := json.Unmarshal(data, &r.Manifest); err != nil {
+		return nil, fmt.Errorf("gagal parsing manifest %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// latestRunManifest returns the manifest in dir of the run that started
+// last. The start is read from the manifest: file names of runs started in
+// the same second (<id>, <id>-2, ...) do not sort in run order.
+func latestRunManifest(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return "", err
+	}
+	var latest string
+	var latestStart time.Time
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var m struct {
+			Start time.Time `json:"start"`
+		}
+		if json.Unmarshal(data, &m) != nil {
+			continue
+		}
+		if latest == "" || m.Start.After(latestStart) {
+			latest, latestStart = f, m.Start
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("belum ada manifest run di %s", dir)
+	}
+	return latest, nil
+}
+
+// Failed returns the index and status of every failed mata kuliah
+func (r *Run) Failed() map[int]MKStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failed := map[int]MKStatus{}
+	for i, st := range r.Manifest.MK {
+		if st.Status == StatusFailed {
+			failed[i] = st
+		}
+	}
+	return failed
+}
+
+// MarkRetried records that the retry mode ran on this manifest
+func (r *Run) MarkRetried(at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.Retried = append(r.Manifest.Retried, at)
+}
+
+// UpdateMK replaces the outcome at index i, e.g. after a retry
+func (r *Run) UpdateMK(i int, st MKStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.MK[i] = st
+}
+
+// AddMK records the outcome of one mata kuliah
+func (r *Run) AddMK(st MKStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.MK = append(r.Manifest.MK, st)
+}
+
+// AddFiles records files written outside of a single mata kuliah
+func (r *Run) AddFiles(paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.Files = append(r.Manifest.Files, paths...)
+}
+
+// AddAnomaly records a grade problem found while writing the reports
+func (r *Run) AddAnomaly(a Anomaly) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest.Anomalies = append(r.Manifest.Anomalies, a)
+}
+
+// Finish closes the run with err and writes its manifest to its run folder.
+// A manifest loaded for a retry keeps its original End, Status and Error: a
+// run-level error (login, SetProdi, an aborted jurusan) is never caused by
+// the MK failures a retry fixes, since those are only recorded per MK. A
+// failed retry is added to Error.
+func (r *Run) Finish(err error) (string, error) {
+	r.mu.Lock()
+	m := &r.Manifest
+	switch {
+	case r.loaded && err != nil:
+		m.Status = StatusFailed
+		if m.Error != "" {
+			m.Error += "; "
+		}
+		m.Error += "retry: " + err.Error()
+	case r.loaded:
+		// status dan error run asli tetap
+	default:
+		m.End = time.Now()
+		m.Status, m.Error = StatusOK, ""
+		if err != nil {
+			m.Status = StatusFailed
+			m.Error = err.Error()
+		}
+	}
+	m.Summary = summarize(m.MK)
+	data, merr := json.MarshalIndent(m, "", "  ")
+	r.mu.Unlock()
+	if merr != nil {
+		return "", merr
+	}
+
+	path := r.path
+	if path == "" {
+		path = filepath.Join(r.dir, m.ID+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return "", fmt.Errorf("gagal buat folder %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("gagal simpan manifest run: %w", err)
+	}
+	r.mu.Lock()
+	r.path = path
+	r.mu.Unlock()
+	return path, nil
+}
+
+func summarize(mk []MKStatus) RunSummary {
+	var sum RunSummary
+	for _, st := range mk {
+		switch st.Status {
+		case StatusOK:
+			sum.OK++
+		case StatusFailed:
+			sum.Failed++
+		case StatusSkipped:
+			sum.Skipped++
+		}
+	}
+	return sum
+}
+
+// Summary counts the MK statuses recorded so far
+func (r *Run) Summary() RunSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return summarize(r.Manifest.MK)
+}
+
+// OutputFiles lists every file the run wrote, MK files first, without duplicates
+func (r *Run) OutputFiles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := map[string]bool{}
+	var files []string
+	add := func(paths []string) {
+		for _, p := range paths {
+			if !seen[p] {
+				seen[p] = true
+				files = append(files, p)
+			}
+		}
+	}
+	for _, st := range r.Manifest.MK {
+		add(st.Files)
+	}
+	add(r.Manifest.Files)
+	return files
+}
+
+// finishRun writes the run manifest, reports where it went and notifies the webhooks
+func finishRun(config *Config, run *Run, err error) {
+	path, ferr := run.Finish(err)
+	report := newRunReport(run, path)
+	// run.finished juga dikirim jika manifest gagal disimpan
+	defer func() { notifyWebhooks(config, EventRunFinished, report) }()
+	if ferr != nil {
+		logf(LogWarn, "Gagal simpan manifest run: %v", ferr)
+		report.ManifestError = ferr.Error()
+		return
+	}
+
+	sum := run.Manifest.Summary
+	logf(LogInfo, "Manifest run disimpan di %s (ok %d, gagal %d, skip %d)", path, sum.OK, sum.Failed, sum.Skipped)
+
+	// run yang dimuat ulang (retry) tidak punya snapshot awal; .prom run
+	// aslinya dibiarkan
+	if run.metrics != nil {
+		metricsPath := strings.TrimSuffix(path, ".json") + ".prom"
+		if err := saveMetrics(metricsPath, run.metrics); err != nil {
+			logf(LogWarn, "Gagal simpan metrics: %v", err)
+		} else {
+			logf(LogInfo, "Metrics run disimpan di %s", metricsPath)
+		}
+	}
+
+	workbook := strings.TrimSuffix(path, ".json") + sheetExt(config.SheetFormat)
+	if err := writeRunWorkbook(workbook, run.Manifest); err != nil {
+		logf(LogWarn, "Gagal tulis workbook run: %v", err)
+		workbook = ""
+	} else {
+		logf(LogInfo, "Workbook run disimpan di %s", workbook)
+	}
+	sendRunEmails(config, run.Manifest, workbook)
+}
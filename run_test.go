@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryKeepsOriginalEnd(t *testing.T) {
+	config := &Config{RunFolder: t.TempDir()}
+	run := newRun(config)
+	path, err := run.Finish(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := run.Manifest.End
+
+	loaded, err := loadRun(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried := end.Add(time.Hour)
+	loaded.MarkRetried(retried)
+	if _, err := loaded.Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := loadRun(filepath.Join(config.RunFolder, run.Manifest.ID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Manifest.End.Equal(end) {
+		t.Errorf("End = %v, want the original %v", again.Manifest.End, end)
+	}
+	if len(again.Manifest.Retried) != 1 || !again.Manifest.Retried[0].Equal(retried) {
+		t.Errorf("Retried = %v, want [%v]", again.Manifest.Retried, retried)
+	}
+}
+
+func TestFinishLoadedRunKeepsMetrics(t *testing.T) {
+	config := &Config{RunFolder: t.TempDir(), SheetFormat: FormatXLSX}
+	run := newRun(config)
+	finishRun(config, run, nil)
+	path := filepath.Join(config.RunFolder, run.Manifest.ID+".json")
+	prom := strings.TrimSuffix(path, ".json") + ".prom"
+	before, err := os.ReadFile(prom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadRun(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricMK.Inc("ok")
+	finishRun(config, loaded, nil)
+
+	after, err := os.ReadFile(prom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("retry overwrote the metrics of the original run")
+	}
+}
+
+func TestLatestRunManifestUsesStart(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 6, 3, 2, 30, 0, 0, time.Local)
+	for name, at := range map[string]time.Time{
+		"20240603-023000.json":   start,
+		"20240603-023000-2.json": start.Add(300 * time.Millisecond),
+		"20240602-010000.json":   start.Add(-24 * time.Hour),
+	} {
+		run := &Run{path: filepath.Join(dir, name), Manifest: RunManifest{ID: strings.TrimSuffix(name, ".json"), Start: at}}
+		if _, err := run.Finish(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := latestRunManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(got) != "20240603-023000-2.json" {
+		t.Errorf("latestRunManifest = %s, want the second run of the same second", filepath.Base(got))
+	}
+	if _, err := latestRunManifest(t.TempDir()); err == nil {
+		t.Error("expected an error for an empty folder")
+	}
+}
+
+func TestRetryKeepsRunError(t *testing.T) {
+	config := &Config{RunFolder: t.TempDir()}
+	run := newRun(config)
+	run.AddMK(MKStatus{Status: StatusFailed, Error: "timeout"})
+	path, err := run.Finish(errors.New("gagal proses jurusan Akuntansi: gagal set prodi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadRun(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.UpdateMK(0, MKStatus{Status: StatusOK, Retries: 1})
+	if _, err := loaded.Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+	m := loaded.Manifest
+	if m.Status != StatusFailed || m.Error != "gagal proses jurusan Akuntansi: gagal set prodi" {
+		t.Errorf("successful retry changed the run outcome: %s %q", m.Status, m.Error)
+	}
+	if m.Summary.OK != 1 || m.Summary.Failed != 0 {
+		t.Errorf("summary = %+v, want the retried MK counted as ok", m.Summary)
+	}
+
+	if _, err := loaded.Finish(errors.New("gagal autentikasi")); err != nil {
+		t.Fatal(err)
+	}
+	if want := "gagal proses jurusan Akuntansi: gagal set prodi; retry: gagal autentikasi"; loaded.Manifest.Error != want {
+		t.Errorf("Error = %q, want %q", loaded.Manifest.Error, want)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// jurusanRunReport is the outcome of one jurusan in a run, used by the
+// consolidated workbook and the email report
+type jurusanRunReport struct {
+	Jurusan   Jurusan
+	Summary   RunSummary
+	Failures  []MKStatus
+	Anomalies []Anomaly
+}
+
+// buildJurusanRunReports groups the MK statuses and anomalies of m per
+// jurusan, in the order the jurusan were processed. only, when not nil,
+// limits the report to those kodejrs.
+func buildJurusanRunReports(m RunManifest, only map[string]bool) []jurusanRunReport {
+	index := map[string]int{}
+	var reports []jurusanRunReport
+	get := func(jur Jurusan) *jurusanRunReport {
+		i, ok := index[jur.KodeJrs]
+		if !ok {
+			i = len(reports)
+			index[jur.KodeJrs] = i
+			reports = append(reports, jurusanRunReport{Jurusan: jur})
+		}
+		return &reports[i]
+	}
+
+	for _, jur := range m.Jurusan {
+		if only == nil || only[jur.KodeJrs] {
+			get(jur)
+		}
+	}
+	for _, st := range m.MK {
+		if only != nil && !only[st.Jurusan.KodeJrs] {
+			continue
+		}
+		r := get(st.Jurusan)
+		switch st.Status {
+		case StatusOK:
+			r.Summary.OK++
+		case StatusSkipped:
+			r.Summary.Skipped++
+		case StatusFailed:
+			r.Summary.Failed++
+			r.Failures = append(r.Failures, st)
+		}
+	}
+	for _, a := range m.Anomalies {
+		if only == nil || only[a.Jurusan.KodeJrs] {
+			r := get(a.Jurusan)
+			r.Anomalies = append(r.Anomalies, a)
+		}
+	}
+	for i := range reports {
+		sort.Slice(reports[i].Failures, func(a, b int) bool {
+			x, y := reports[i].Failures[a].MataKuliah, reports[i].Failures[b].MataKuliah
+			if x.KodeMK != y.KodeMK {
+				return x.KodeMK < y.KodeMK
+			}
+			return x.Kelas < y.Kelas
+		})
+	}
+	return reports
+}
+
+// runWorkbookHeaders are the columns of the "Ringkasan" sheet
+var runWorkbookHeaders = []string{"Jurusan", "Semester", "MK Tersimpan", "MK Skip", "MK Gagal", "Perubahan Nilai", "Selisih Nilai", "Huruf Tidak Sesuai", "Dosen Nilai Belum Lengkap"}
+
+// writeRunWorkbook writes the consolidated workbook of a run: a summary per
+// jurusan, every MK status and every anomaly
+func writeRunWorkbook(path string, m RunManifest) error {
+	reports := buildJurusanRunReports(m, nil)
+
+	ringkasan := [][]interface{}{headerRow(runWorkbookHeaders)}
+	for _, r := range reports {
+		count := map[string]int{}
+		for _, a := range r.Anomalies {
+			count[a.Jenis] += a.Jumlah
+		}
+		ringkasan = append(ringkasan, []interface{}{
+			r.Jurusan.NamaJrs, m.Semester, r.Summary.OK, r.Summary.Skipped, r.Summary.Failed,
+			count[AnomaliPerubahan], count[AnomaliSelisihNilai], count[AnomaliHuruf], count[AnomaliBelumLengkap],
+		})
+	}
+	ringkasan = append(ringkasan,
+		[]interface{}{},
+		[]interface{}{"Run", m.ID},
+		[]interface{}{"Mode", m.Mode},
+		[]interface{}{"Status", m.Status},
+		[]interface{}{"Mulai", m.Start.Format(time.DateTime)},
+		[]interface{}{"Selesai", m.End.Format(time.DateTime)},
+	)
+
+	mk := [][]interface{}{headerRow([]string{"Jurusan", "Kode MK", "Nama MK", "Kelas", "Dosen", "Status", "Keterangan", "Retry", "Baris"})}
+	for _, st := range m.MK {
+		mk = append(mk, []interface{}{
+			st.Jurusan.NamaJrs, st.MataKuliah.KodeMK, st.MataKuliah.Namamk, st.MataKuliah.Kelas,
+			st.MataKuliah.Namadosen, st.Status, st.Error, st.Retries, st.Rows,
+		})
+	}
+
+	anomali := [][]interface{}{headerRow([]string{"Jurusan", "Semester", "Jenis", "Jumlah", "Keterangan"})}
+	for _, a := range m.Anomalies {
+		anomali = append(anomali, []interface{}{a.Jurusan.NamaJrs, a.Semester, a.Jenis, a.Jumlah, a.Keterangan})
+	}
+
+	return writeTables(path,
+		sheetTable{Name: "Ringkasan", Rows: ringkasan},
+		sheetTable{Name: "MK", Rows: mk},
+		sheetTable{Name: "Anomali", Rows: anomali},
+	)
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 type Scraper struct {
@@ -35,7 +37,7 @@ func (s *Scraper) DoRequest(method, endpoint string, body io.Reader) ([]byte, er
 	ua := userAgents[rand.Intn(len(userAgents))]
 	req.Header.Set(HeaderUserAgent, ua)
 	req.Header.Set(HeaderXRequestedWith, XMLHttpRequest)
-	req.Header.Set(HeaderReferer, s.baseURL+MediaEndpoint)
+	req.Header.Set(HeaderReferer, s.baseURL+s.config.Deployment.Endpoints.Media)
 	req.Header.Set(HeaderOrigin, s.baseURL)
 	req.Header.Set(HeaderAccept, AcceptJSON)
 	if body != nil {
@@ -45,21 +47,33 @@ func (s *Scraper) DoRequest(method, endpoint string, body io.Reader) ([]byte, er
 		req.Header.Set(HeaderCookie, s.cookie)
 	}
 
+	start := time.Now()
 	res, err := s.client.Do(req)
+	observeRequest(endpoint, start, res, err)
 	if err != nil {
+		slog.Debug("request gagal", "method", method, "endpoint", endpoint, "latency", time.Since(start), "error", err)
 		return nil, fmt.Errorf("gagal kirim request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
+		slog.Debug("request", "method", method, "endpoint", endpoint, "status", res.StatusCode, "latency", time.Since(start))
 		return nil, fmt.Errorf("request gagal status: %d", res.StatusCode)
 	}
-	return io.ReadAll(res.Body)
+	data, err := io.ReadAll(res.Body)
+	slog.Debug("request", "method", method, "endpoint", endpoint, "status", res.StatusCode, "latency", time.Since(start), "bytes", len(data))
+	return data, err
 }
 
 func (s *Scraper) GetBobotMK(fak, kodeProdi, kodePK, kls, kmk string) (Bobot, error) {
-	data := "fak=" + fak + "&jrs=" + kodeProdi + "&prg=" + kodePK + "&kls=" + kls + "&kmk=" + kmk
-	body, err := s.DoRequest(POST, "/_modul/mod_nilmk/aksi_nilmk.php?act=loadBOBOT", strings.NewReader(data))
+	fields := s.config.Deployment.Fields
+	form := url.Values{}
+	form.Set(fields.BobotFak, fak)
+	form.Set(fields.BobotJurusan, kodeProdi)
+	form.Set(fields.BobotProgram, kodePK)
+	form.Set(fields.BobotKelas, kls)
+	form.Set(fields.BobotKodeMK, kmk)
+	body, err := s.DoRequest(POST, s.config.Deployment.Endpoints.Bobot, strings.NewReader(form.Encode()))
 	if err != nil {
 		return Bobot{}, err
 	}
@@ -72,8 +86,8 @@ func (s *Scraper) GetBobotMK(fak, kodeProdi, kodePK, kls, kmk string) (Bobot, er
 }
 
 func (s *Scraper) GetRekapMHS() (*RekapMHSResponse, error) {
-	data := "page=1&rows=500&"
-	body, err := s.DoRequest(POST, "/_modul/mod_datamhs/aksi_datamhs.php?act=list", strings.NewReader(data))
+	data := s.config.Deployment.RekapMHSBody
+	body, err := s.DoRequest(POST, s.config.Deployment.Endpoints.RekapMHS, strings.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -85,27 +99,33 @@ func (s *Scraper) GetRekapMHS() (*RekapMHSResponse, error) {
 }
 
 func (s *Scraper) IsSessionValid() bool {
-	body, err := s.DoRequest(GET, MediaEndpoint, nil)
+	body, err := s.DoRequest(GET, s.config.Deployment.Endpoints.Media, nil)
 	if err != nil {
 		logf(LogError, "Gagal cek session: %v", err)
 		return false
 	}
 	// logf(LogInfo, "Response Body : %s", string(body))
-	return !(strings.Contains(string(body), "window.location = 'index.php'") || strings.Contains(string(body), "login") || strings.Contains(string(body), "Username"))
+	for _, marker := range s.config.Deployment.LoggedOutMarkers {
+		if strings.Contains(string(body), marker) {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *Scraper) SetProdi(kodeProdi, kodePK, smthn string) error {
+	fields := s.config.Deployment.Fields
 	form := url.Values{}
-	form.Set(FormPS, kodeProdi)
-	form.Set(FormPK, kodePK)
-	form.Set(FormSMTHN, smthn)
-	_, err := s.DoRequest(POST, "/_modul/mod_prodi_smthn/aksi_prodi_smthn.php", strings.NewReader(form.Encode()))
+	form.Set(fields.Prodi, kodeProdi)
+	form.Set(fields.ProgramKelas, kodePK)
+	form.Set(fields.Semester, smthn)
+	_, err := s.DoRequest(POST, s.config.Deployment.Endpoints.SetProdi, strings.NewReader(form.Encode()))
 	return err
 }
 
 func (s *Scraper) GetRekapMK() (*RekapMKResponse, error) {
-	data := "page=1&rows=300&sort=hari&order=asc"
-	body, err := s.DoRequest(POST, "/_modul/mod_nilmk/aksi_nilmk.php?act=rekapNILMK", strings.NewReader(data))
+	data := s.config.Deployment.RekapMKBody
+	body, err := s.DoRequest(POST, s.config.Deployment.Endpoints.RekapMK, strings.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -117,10 +137,11 @@ func (s *Scraper) GetRekapMK() (*RekapMKResponse, error) {
 }
 
 func (s *Scraper) GetListNilai(infomk string) ([]Nilai, error) {
+	fields := s.config.Deployment.Fields
 	form := url.Values{}
-	form.Set(FormParam, infomk)
-	form.Set(FormCetak, CetakValue)
-	body, err := s.DoRequest(POST, "/_modul/mod_nilmk/aksi_nilmk.php?act=listNILMK", strings.NewReader(form.Encode()))
+	form.Set(fields.Param, infomk)
+	form.Set(fields.Cetak, CetakValue)
+	body, err := s.DoRequest(POST, s.config.Deployment.Endpoints.ListNilai, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -131,21 +152,44 @@ func (s *Scraper) GetListNilai(infomk string) ([]Nilai, error) {
 	return hasil, nil
 }
 
-// loadJurusan loads the jurusan data from file
-func loadJurusan() (Jurusan, error) {
+// readJurusanList reads every jurusan from config.JurusanFile
+func readJurusanList(config *Config) ([]Jurusan, error) {
 	// baca file jurusan.json (atau bisa juga dari API kalau ada)
-	data, err := os.ReadFile(JurusanFile)
+	data, err := os.ReadFile(config.JurusanFile)
 	if err != nil {
-		return Jurusan{}, fmt.Errorf("gagal baca %s: %w", JurusanFile, err)
+		return nil, fmt.Errorf("gagal baca %s: %w", config.JurusanFile, err)
 	}
 
 	var jurusanList []Jurusan
 	if err := json.Unmarshal(data, &jurusanList); err != nil {
-		return Jurusan{}, fmt.Errorf("gagal parsing JSON jurusan: %w", err)
+		return nil, fmt.Errorf("gagal parsing JSON jurusan: %w", err)
 	}
 
 	if len(jurusanList) == 0 {
-		return Jurusan{}, fmt.Errorf("tidak ada jurusan yang tersedia")
+		return nil, fmt.Errorf("tidak ada jurusan yang tersedia")
+	}
+	return jurusanList, nil
+}
+
+// findJurusan looks a jurusan up by kodejrs or namajrs
+func findJurusan(config *Config, key string) (Jurusan, error) {
+	jurusanList, err := readJurusanList(config)
+	if err != nil {
+		return Jurusan{}, err
+	}
+	for _, j := range jurusanList {
+		if j.KodeJrs == key || strings.EqualFold(j.NamaJrs, key) {
+			return j, nil
+		}
+	}
+	return Jurusan{}, fmt.Errorf("jurusan %s tidak ditemukan di %s", key, config.JurusanFile)
+}
+
+// loadJurusan loads the jurusan data from file
+func loadJurusan(config *Config) (Jurusan, error) {
+	jurusanList, err := readJurusanList(config)
+	if err != nil {
+		return Jurusan{}, err
 	}
 
 	// tampilkan daftar
@@ -160,8 +204,7 @@ func loadJurusan() (Jurusan, error) {
 	// pilih input
 	var selection int
 	fmt.Printf("[INFO] Pilih jurusan (nomor): ")
-	_, err = fmt.Scan(&selection)
-	if err != nil {
+	if _, err := fmt.Scan(&selection); err != nil {
 		return Jurusan{}, fmt.Errorf("gagal membaca input: %w", err)
 	}
 
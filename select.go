@@ -6,17 +6,26 @@ import (
 	"os"
 )
 
-func (s *Scraper) SelectSemester() (string, error) {
-	body, err := s.DoRequest(POST, "/_modul/aksi_umum.php?act=pilih_smtthnakd", nil)
+// ListSemesters returns the semesters SIAKAD offers, newest first
+func (s *Scraper) ListSemesters() ([]Semester, error) {
+	body, err := s.DoRequest(POST, s.config.Deployment.Endpoints.Semesters, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	var semesters []Semester
 	if err := json.Unmarshal(body, &semesters); err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(semesters) == 0 {
-		return "", fmt.Errorf("tidak ada semester")
+		return nil, fmt.Errorf("tidak ada semester")
+	}
+	return semesters, nil
+}
+
+func (s *Scraper) SelectSemester() (string, error) {
+	semesters, err := s.ListSemesters()
+	if err != nil {
+		return "", err
 	}
 
 	printHeader("Daftar Semester", nil)
@@ -30,8 +39,8 @@ func (s *Scraper) SelectSemester() (string, error) {
 	return semesters[sel-1].Smtthnakd, nil
 }
 
-func SelectJurusan() (Jurusan, error) {
-	data, err := os.ReadFile(JurusanFile)
+func SelectJurusan(config *Config) (Jurusan, error) {
+	data, err := os.ReadFile(config.JurusanFile)
 	if err != nil {
 		return Jurusan{}, err
 	}
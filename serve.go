@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// runServe serves the web dashboard on / and a REST API for scrape jobs:
+//
+//	POST /auth/login                 login dashboard ({"username","password"})
+//	POST /auth/logout                hapus sesi dashboard
+//	GET  /api/semesters              daftar semester dari SIAKAD
+//	GET  /api/jurusan                isi jurusan.json
+//	GET  /api/jobs                   semua job
+//	POST /api/jobs                   submit ScrapeSpec sebagai job baru
+//	GET  /api/jobs/{id}              status, progress dan file sebuah job
+//	GET  /api/jobs/{id}/files/{n}    unduh file ke-n dari job
+//	GET  /api/files/{root}/{path...} isi folder (json/excel) atau unduh file
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "alamat listen HTTP")
+	fs.Parse(args)
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("gagal load konfigurasi: %w", err)
+	}
+	scraper := NewScraper(config)
+	manager := newJobManager(scraper)
+	if err := manager.authenticate(); err != nil {
+		return err
+	}
+	logf(LogInfo, "Login Sebagai: %s", config.Username)
+
+	auth := newToolAuth(config)
+	if !auth.enabled() {
+		logf(LogWarn, "DASHBOARD_USER/DASHBOARD_PASSWORD kosong, dashboard dan API tanpa login")
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: dashboardHandler(manager, auth)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	logf(LogInfo, "Dashboard berjalan di http://%s/", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("gagal menjalankan server: %w", err)
+	}
+	logf(LogInfo, "Menunggu job yang berjalan selesai...")
+	manager.Shutdown()
+	return nil
+}
+
+func apiHandler(manager *JobManager) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/semesters", func(w http.ResponseWriter, r *http.Request) {
+		semesters, err := manager.Semesters()
+		if err != nil {
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, semesters)
+	})
+
+	mux.HandleFunc("GET /api/jurusan", func(w http.ResponseWriter, r *http.Request) {
+		jurusan, err := readJurusanList(manager.scraper.config)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, jurusan)
+	})
+
+	mux.HandleFunc("GET /api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, manager.List())
+	})
+
+	mux.HandleFunc("POST /api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		var spec ScrapeSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Errorf("body tidak valid: %w", err))
+			return
+		}
+		job, err := manager.Submit(spec, "")
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Location", "/api/jobs/"+job.ID)
+		respondJSON(w, http.StatusAccepted, job)
+	})
+
+	mux.HandleFunc("GET /api/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, ok := manager.Get(r.PathValue("id"))
+		if !ok {
+			respondError(w, http.StatusNotFound, fmt.Errorf("job %s tidak ditemukan", r.PathValue("id")))
+			return
+		}
+		respondJSON(w, http.StatusOK, job)
+	})
+
+	mux.HandleFunc("GET /api/jobs/{id}/files/{n}", func(w http.ResponseWriter, r *http.Request) {
+		job, ok := manager.Get(r.PathValue("id"))
+		if !ok {
+			respondError(w, http.StatusNotFound, fmt.Errorf("job %s tidak ditemukan", r.PathValue("id")))
+			return
+		}
+		n, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil || n < 0 || n >= len(job.Files) {
+			respondError(w, http.StatusNotFound, fmt.Errorf("file %s tidak ada di job %s", r.PathValue("n"), job.ID))
+			return
+		}
+		path := job.Files[n]
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+		http.ServeFile(w, r, path)
+	})
+
+	mux.HandleFunc("GET /api/files/{root}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		handleFiles(manager.scraper.config, w, r)
+	})
+
+	return mux
+}
+
+func respondJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func respondError(w http.ResponseWriter, status int, err error) {
+	respondJSON(w, status, map[string]string{"error": err.Error()})
+}
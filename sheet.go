@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetTable is a format-neutral worksheet: the first row is usually the header
+type sheetTable struct {
+	Name string
+	Rows [][]interface{}
+	Text bool // force every cell to the text number format (xlsx only)
+}
+
+// sheetExt returns the file extension for the configured spreadsheet format
+func sheetExt(format string) string {
+	if format == FormatODS {
+		return ".ods"
+	}
+	return ".xlsx"
+}
+
+func headerRow(headers []string) []interface{} {
+	row := make([]interface{}, len(headers))
+	for i, h := range headers {
+		row[i] = h
+	}
+	return row
+}
+
+// writeTables writes the tables to path, choosing the writer from the extension
+func writeTables(path string, tables ...sheetTable) error {
+	if strings.EqualFold(filepath.Ext(path), ".ods") {
+		return writeODS(path, tables)
+	}
+	return writeXLSX(path, tables)
+}
+
+func writeXLSX(path string, tables []sheetTable) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, t := range tables {
+		if i == 0 {
+			if t.Name != "Sheet1" {
+				if err := f.SetSheetName("Sheet1", t.Name); err != nil {
+					return err
+				}
+			}
+		} else if _, err := f.NewSheet(t.Name); err != nil {
+			return err
+		}
+
+		style := 0
+		if t.Text {
+			s, err := f.NewStyle(&excelize.Style{NumFmt: 49})
+			if err != nil {
+				return err
+			}
+			style = s
+		}
+
+		for r, row := range t.Rows {
+			for c, v := range row {
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+1)
+				f.SetCellValue(t.Name, cell, v)
+				if style != 0 {
+					f.SetCellStyle(t.Name, cell, cell, style)
+				}
+			}
+		}
+	}
+	return f.SaveAs(path)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// MutuLulus is the minimum quality point counted as passing
+const MutuLulus = 2.0
+
+// ClassStats summarizes the nil_angka distribution of one class
+type ClassStats struct {
+	KodeMK      string         `json:"kodemk"`
+	Namamk      string         `json:"namamk"`
+	Kelas       string         `json:"kelas"`
+	Namadosen   string         `json:"namadosen"`
+	Jumlah      int            `json:"jumlah"`
+	Rata        float64        `json:"rata"`
+	Median      float64        `json:"median"`
+	StdDev      float64        `json:"std_dev"`
+	Min         float64        `json:"min"`
+	Max         float64        `json:"max"`
+	Distribusi  map[string]int `json:"distribusi"`
+	Lulus       int            `json:"lulus"`
+	PersenLulus float64        `json:"persen_lulus"`
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// computeClassStats calculates the statistics of a class; rows without a
+// numeric nil_angka are left out of the numeric figures.
+func computeClassStats(res mkResult, scale GradeScale) ClassStats {
+	mk := res.MataKuliah
+	st := ClassStats{
+		KodeMK:     mk.KodeMK,
+		Namamk:     mk.Namamk,
+		Kelas:      mk.Kelas,
+		Namadosen:  mk.Namadosen,
+		Distribusi: map[string]int{},
+	}
+
+	var values []float64
+	for _, n := range res.Nilai {
+		angka, ok := parseScore(n.NilAngka)
+		if !ok {
+			continue
+		}
+		values = append(values, angka)
+
+		huruf := strings.ToUpper(strings.TrimSpace(n.NilHuruf))
+		if huruf == "" {
+			huruf = scale.Huruf(angka)
+		}
+		st.Distribusi[huruf]++
+		if mutu, ok := scale.Mutu(huruf); ok && mutu >= MutuLulus {
+			st.Lulus++
+		}
+	}
+
+	st.Jumlah = len(values)
+	if st.Jumlah == 0 {
+		return st
+	}
+	sort.Float64s(values)
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(st.Jumlah)
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+
+	median := values[st.Jumlah/2]
+	if st.Jumlah%2 == 0 {
+		median = (values[st.Jumlah/2-1] + values[st.Jumlah/2]) / 2
+	}
+
+	st.Rata = round2(mean)
+	st.Median = round2(median)
+	st.StdDev = round2(math.Sqrt(sq / float64(st.Jumlah)))
+	st.Min = values[0]
+	st.Max = values[st.Jumlah-1]
+	st.PersenLulus = round2(float64(st.Lulus) * 100 / float64(st.Jumlah))
+	return st
+}
+
+// writeStatistics writes the per-class statistics as "Statistik Nilai" JSON and spreadsheet
+func writeStatistics(cfg *Config, out mkOutput, stats []ClassStats, scale GradeScale) ([]string, error) {
+	headers := []interface{}{"Kode MK", "Nama MK", "Kelas", "Dosen", "Jumlah", "Rata-rata", "Median", "Std Dev", "Min", "Max", "Lulus", "% Lulus"}
+	for _, g := range scale.Nilai {
+		headers = append(headers, g.Huruf)
+	}
+	headers = append(headers, "Lainnya")
+
+	rows := [][]interface{}{headers}
+	for _, st := range stats {
+		row := []interface{}{st.KodeMK, st.Namamk, st.Kelas, st.Namadosen, st.Jumlah, st.Rata, st.Median, st.StdDev, st.Min, st.Max, st.Lulus, st.PersenLulus}
+		lain := st.Jumlah
+		for _, g := range scale.Nilai {
+			row = append(row, st.Distribusi[g.Huruf])
+			lain -= st.Distribusi[g.Huruf]
+		}
+		rows = append(rows, append(row, lain))
+	}
+	return writeReport(cfg, out, "Statistik Nilai", stats, sheetTable{Name: "Statistik", Rows: rows})
+}
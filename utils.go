@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
@@ -9,12 +11,19 @@ import (
 	"time"
 )
 
+// log writes message through the default slog logger at the level of the
+// label, see logLevels. An unknown label is logged at info level.
 func log(level, message string) {
-	fmt.Printf("%s %s\n", level, message)
+	l, ok := logLevels[level]
+	if !ok {
+		slog.Info(message, "label", level)
+		return
+	}
+	slog.Log(context.Background(), l, message)
 }
 
 func logf(level, format string, args ...interface{}) {
-	fmt.Printf("%s %s\n", level, fmt.Sprintf(format, args...))
+	log(level, fmt.Sprintf(format, args...))
 }
 
 func clearScreen() {
@@ -53,23 +62,6 @@ func sanitizeFilename(name string) string {
 	return strings.TrimSpace(replacer.Replace(name))
 }
 
-func updateProgress(label string, done, total int, lastPercent *int) {
-	if total == 0 {
-		return
-	}
-	percent := done * 100 / total
-	if percent-*lastPercent >= 5 || percent == 100 {
-		barLen := ProgressBarLength
-		pos := percent * barLen / 100
-
-		// pakai blok penuh █ dan filler spasi
-		bar := strings.Repeat("█", pos) + strings.Repeat(" ", barLen-pos)
-
-		fmt.Printf("\r[PROGRESS] %s: [%s] %d%% (%d/%d)", label, bar, percent, done, total)
-		*lastPercent = percent
-	}
-}
-
 func formatDuration(d time.Duration) string {
 	seconds := int(d.Seconds())
 	if seconds < 60 {
@@ -0,0 +1,219 @@
+"use strict";
+
+const $ = (sel) => document.querySelector(sel);
+let fileRoot = "json";
+let filePath = "";
+let pollTimer = null;
+
+async function api(method, url, body) {
+  const res = await fetch(url, {
+    method,
+    headers: body ? { "Content-Type": "application/json" } : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await res.json().catch(() => ({}));
+  if (res.status === 401 && url.startsWith("/api/")) {
+    showLogin();
+  }
+  if (!res.ok) {
+    throw new Error(data.error || res.statusText);
+  }
+  return data;
+}
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs)) {
+    if (k.startsWith("on")) node.addEventListener(k.slice(2), v);
+    else node.setAttribute(k, v);
+  }
+  for (const c of children) node.append(c);
+  return node;
+}
+
+function showLogin() {
+  $("#login").hidden = false;
+  $("#app").hidden = true;
+  $("#logout").hidden = true;
+  clearTimeout(pollTimer);
+}
+
+async function showApp(required) {
+  $("#login").hidden = true;
+  $("#app").hidden = false;
+  $("#logout").hidden = !required;
+  await Promise.all([loadSemesters(), loadJurusan(), loadJobs(), loadFiles()]);
+}
+
+async function loadSemesters() {
+  const sel = $("#semester");
+  sel.replaceChildren(el("option", { value: "" }, "memuat..."));
+  try {
+    const semesters = await api("GET", "/api/semesters");
+    sel.replaceChildren(...semesters.map((s) => el("option", { value: s.smtthnakd }, `${s.keterangan} (${s.smtthnakd})`)));
+  } catch (err) {
+    sel.replaceChildren(el("option", { value: "" }, "gagal memuat semester"));
+    $("#job-error").textContent = err.message;
+  }
+}
+
+async function loadJurusan() {
+  const jurusan = await api("GET", "/api/jurusan");
+  $("#jurusan").replaceChildren(
+    ...jurusan.map((j) => el("label", {}, el("input", { type: "checkbox", name: "jurusan", value: j.kodejrs }), j.namajrs))
+  );
+}
+
+function progressBars(job) {
+  if (!job.progress || job.progress.length === 0) return "";
+  const wrap = el("div");
+  for (const p of job.progress) {
+    const pct = p.total ? Math.floor((p.done * 100) / p.total) : 100;
+    const fill = el("span");
+    fill.style.width = pct + "%";
+    wrap.append(el("div", { class: "bar" }, fill, el("small", {}, `${p.label}: ${p.done}/${p.total}`)));
+  }
+  return wrap;
+}
+
+function jobFiles(job) {
+  if (!job.files || job.files.length === 0) return "";
+  const list = el("ul");
+  job.files.forEach((f, i) => {
+    list.append(el("li", {}, el("a", { href: `/api/jobs/${job.id}/files/${i}` }, f)));
+  });
+  return el("details", {}, el("summary", {}, `${job.files.length} file`), list);
+}
+
+async function loadJobs() {
+  clearTimeout(pollTimer);
+  const jobs = await api("GET", "/api/jobs");
+  $("#jobs").replaceChildren(
+    ...jobs.reverse().map((job) =>
+      el(
+        "tr",
+        {},
+        el("td", {}, job.id),
+        el("td", {}, job.spec.mode),
+        el("td", {}, job.spec.semester),
+        el("td", { class: "status-" + job.status, title: job.error || "" }, job.status + (job.error ? ": " + job.error : "")),
+        el("td", {}, progressBars(job)),
+        el("td", {}, `${job.summary.ok} / ${job.summary.failed} / ${job.summary.skipped}`),
+        el("td", {}, jobFiles(job))
+      )
+    )
+  );
+  const active = jobs.some((j) => j.status === "queued" || j.status === "running");
+  pollTimer = setTimeout(() => loadJobs().catch(() => {}), active ? 1000 : 5000);
+}
+
+function formatSize(n) {
+  if (n < 1024) return n + " B";
+  if (n < 1024 * 1024) return (n / 1024).toFixed(1) + " KB";
+  return (n / 1024 / 1024).toFixed(1) + " MB";
+}
+
+async function loadFiles() {
+  const crumbs = [el("a", { onclick: () => openDir("") }, fileRoot === "json" ? "nilai_json" : "nilai_excel")];
+  let acc = "";
+  for (const part of filePath.split("/").filter(Boolean)) {
+    acc = acc ? acc + "/" + part : part;
+    const target = acc;
+    crumbs.push(" / ", el("a", { onclick: () => openDir(target) }, part));
+  }
+  $("#breadcrumb").replaceChildren(...crumbs);
+
+  let entries = [];
+  try {
+    entries = await api("GET", `/api/files/${fileRoot}/${encodePath(filePath)}`);
+  } catch (err) {
+    $("#files").replaceChildren(el("tr", {}, el("td", { colspan: "3" }, err.message)));
+    return;
+  }
+  $("#files").replaceChildren(
+    ...entries.map((e) =>
+      el(
+        "tr",
+        {},
+        el(
+          "td",
+          {},
+          e.dir
+            ? el("a", { onclick: () => openDir(e.path) }, "📁 " + e.name)
+            : el("a", { href: `/api/files/${fileRoot}/${encodePath(e.path)}` }, e.name)
+        ),
+        el("td", {}, e.dir ? "" : formatSize(e.size)),
+        el("td", {}, new Date(e.modified).toLocaleString("id-ID"))
+      )
+    )
+  );
+}
+
+function encodePath(p) {
+  return p.split("/").map(encodeURIComponent).join("/");
+}
+
+function openDir(p) {
+  filePath = p;
+  loadFiles();
+}
+
+$("#login-form").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  const form = new FormData(ev.target);
+  try {
+    await api("POST", "/auth/login", { username: form.get("username"), password: form.get("password") });
+    $("#login-error").textContent = "";
+    ev.target.reset();
+    await showApp(true);
+  } catch (err) {
+    $("#login-error").textContent = err.message;
+  }
+});
+
+$("#logout").addEventListener("click", async () => {
+  await api("POST", "/auth/logout");
+  showLogin();
+});
+
+$("#pilih-semua").addEventListener("click", () => {
+  const boxes = [...document.querySelectorAll("#jurusan input")];
+  const all = boxes.every((b) => b.checked);
+  boxes.forEach((b) => (b.checked = !all));
+});
+
+$("#job-form").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  const form = new FormData(ev.target);
+  const jurusan = form.getAll("jurusan");
+  if (jurusan.length === 0) {
+    $("#job-error").textContent = "Pilih minimal satu jurusan";
+    return;
+  }
+  try {
+    await api("POST", "/api/jobs", {
+      mode: form.get("mode"),
+      semester: form.get("semester"),
+      jurusan,
+      tahun: form.get("tahun").trim(),
+    });
+    $("#job-error").textContent = "";
+    await loadJobs();
+  } catch (err) {
+    $("#job-error").textContent = err.message;
+  }
+});
+
+document.querySelectorAll(".tabs button").forEach((btn) =>
+  btn.addEventListener("click", () => {
+    document.querySelectorAll(".tabs button").forEach((b) => b.classList.remove("active"));
+    btn.classList.add("active");
+    fileRoot = btn.dataset.root;
+    filePath = "";
+    loadFiles();
+  })
+);
+
+api("GET", "/auth/me")
+  .then((me) => (me.login ? showApp(me.required) : showLogin()))
+  .catch(showLogin);
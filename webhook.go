@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Webhook events
+const (
+	EventRunFinished    = "run.finished"
+	EventLoginFailed    = "login.failed"
+	EventSetProdiFailed = "setprodi.failed"
+	EventTest           = "test"
+)
+
+// Webhook request headers. The signature is
+// "sha256=" + hex(HMAC-SHA256(WEBHOOK_SECRET, timestamp + "." + body)).
+const (
+	HeaderWebhookEvent     = "X-Scraper-Event"
+	HeaderWebhookDelivery  = "X-Scraper-Delivery"
+	HeaderWebhookTimestamp = "X-Scraper-Timestamp"
+	HeaderWebhookSignature = "X-Scraper-Signature"
+)
+
+// WebhookTimeout bounds a single delivery attempt; WebhookConcurrency bounds
+// the deliveries in flight at once
+const (
+	WebhookTimeout     = 10 * time.Second
+	WebhookConcurrency = 4
+
+	// WebhookWait is how long the process waits for pending deliveries on exit
+	WebhookWait = time.Minute
+)
+
+// webhookSlots limits concurrent deliveries; webhookPending tracks the
+// deliveries not finished yet so the process can wait for them before exiting
+var (
+	webhookSlots   = make(chan struct{}, WebhookConcurrency)
+	webhookPending sync.WaitGroup
+)
+
+// WebhookEvent is the JSON body posted to every webhook URL
+type WebhookEvent struct {
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Host  string      `json:"host"`
+	User  string      `json:"user"`
+	Data  interface{} `json:"data"`
+}
+
+// RunReport is the data of a run.finished event
+type RunReport struct {
+	RunID      string     `json:"run_id"`
+	Mode       string     `json:"mode"`
+	Semester   string     `json:"semester"`
+	Jurusan    []Jurusan  `json:"jurusan"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Summary    RunSummary `json:"summary"`
+	DurationMS int64      `json:"duration_ms"`
+	Failures   []MKStatus `json:"failures,omitempty"`
+	Files      []string   `json:"files,omitempty"`
+	Manifest   string     `json:"manifest"`
+
+	// ManifestError is set when the manifest could not be saved
+	ManifestError string `json:"manifest_error,omitempty"`
+}
+
+// SetProdiFailure is the data of a setprodi.failed event
+type SetProdiFailure struct {
+	KodeProdi string `json:"kode_prodi"`
+	KodePK    string `json:"kode_pk"`
+	Semester  string `json:"semester"`
+	Error     string `json:"error"`
+}
+
+// newRunReport summarizes a finished run for run.finished
+func newRunReport(run *Run, manifest string) RunReport {
+	files := run.OutputFiles()
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	m := run.Manifest
+	report := RunReport{
+		RunID:      m.ID,
+		Mode:       m.Mode,
+		Semester:   m.Semester,
+		Jurusan:    m.Jurusan,
+		Status:     m.Status,
+		Error:      m.Error,
+		Summary:    m.Summary,
+		DurationMS: m.End.Sub(m.Start).Milliseconds(),
+		Files:      files,
+		Manifest:   manifest,
+	}
+	for _, st := range m.MK {
+		if st.Status == StatusFailed {
+			report.Failures = append(report.Failures, st)
+		}
+	}
+	return report
+}
+
+// notifySetProdiFailed sends setprodi.failed for jur in semester
+func notifySetProdiFailed(config *Config, jur Jurusan, semester string, err error) {
+	notifyWebhooks(config, EventSetProdiFailed, SetProdiFailure{KodeProdi: jur.KodeJrs, KodePK: config.RegValue, Semester: semester, Error: err.Error()})
+}
+
+// notifyWebhooks posts event to every WEBHOOK_URLS entry in the background,
+// retrying each up to WEBHOOK_RETRIES times, so a slow receiver never holds up
+// scraping. Failures are only logged. waitWebhooks waits for the deliveries.
+func notifyWebhooks(config *Config, event string, data interface{}) {
+	if len(config.WebhookURLs) == 0 {
+		return
+	}
+	host := config.BaseURL
+	if u, err := url.Parse(config.BaseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	body, err := json.Marshal(WebhookEvent{Event: event, Time: time.Now(), Host: host, User: config.Username, Data: data})
+	if err != nil {
+		logf(LogError, "Gagal encode webhook %s: %v", event, err)
+		return
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	delivery := hex.EncodeToString(id)
+
+	for _, target := range config.WebhookURLs {
+		webhookPending.Add(1)
+		go func(target string) {
+			defer webhookPending.Done()
+			webhookSlots <- struct{}{}
+			defer func() { <-webhookSlots }()
+			if err := deliverWebhook(config, target, event, delivery, body); err != nil {
+				logf(LogWarn, "Webhook %s ke %s gagal: %v", event, target, err)
+				return
+			}
+			logf(LogDebug, "Webhook %s terkirim ke %s", event, target)
+		}(target)
+	}
+}
+
+// waitWebhooks waits up to timeout for the deliveries still in flight and
+// reports whether all of them finished
+func waitWebhooks(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		webhookPending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		logf(LogWarn, "Webhook belum selesai terkirim setelah %s, dilewati", timeout)
+		return false
+	}
+}
+
+func deliverWebhook(config *Config, target, event, delivery string, body []byte) error {
+	client := &http.Client{Timeout: WebhookTimeout}
+	var err error
+	for attempt := 1; attempt <= config.WebhookRetries; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(1<<(attempt-2)) * time.Second)
+		}
+		if err = postWebhook(client, config.WebhookSecret, target, event, delivery, body); err == nil {
+			return nil
+		}
+		logf(LogDebug, "Webhook %s ke %s percobaan %d gagal: %v", event, target, attempt, err)
+	}
+	return err
+}
+
+func postWebhook(client *http.Client, secret, target, event, delivery string, body []byte) error {
+	req, err := http.NewRequest(POST, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderContentType, "application/json")
+	req.Header.Set(HeaderWebhookEvent, event)
+	req.Header.Set(HeaderWebhookDelivery, delivery)
+	req.Header.Set(HeaderWebhookTimestamp, ts)
+	if secret != "" {
+		req.Header.Set(HeaderWebhookSignature, signWebhook(secret, ts, body))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func signWebhook(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// runWebhook sends a test event to the configured webhooks
+func runWebhook(args []string) error {
+	fs := flag.NewFlagSet("webhook", flag.ExitOnError)
+	fs.Parse(args)
+
+	config, err := loadOptions()
+	if err != nil {
+		return fmt.Errorf("gagal load konfigurasi: %w", err)
+	}
+	if len(config.WebhookURLs) == 0 {
+		return fmt.Errorf("WEBHOOK_URLS kosong")
+	}
+	logf(LogInfo, "Kirim event %s ke %d webhook", EventTest, len(config.WebhookURLs))
+	notifyWebhooks(config, EventTest, map[string]string{"pesan": "tes webhook"})
+	waitWebhooks(WebhookWait)
+	return nil
+}
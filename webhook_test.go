@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"event":"test"}`)
+	mac := hmac.New(sha256.New, []byte("rahasia"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := signWebhook("rahasia", "1700000000", body); got != want {
+		t.Fatalf("signWebhook = %s, want %s", got, want)
+	}
+	if signWebhook("lain", "1700000000", body) == want {
+		t.Fatal("signature does not depend on the secret")
+	}
+	if signWebhook("rahasia", "1700000001", body) == want {
+		t.Fatal("signature does not depend on the timestamp")
+	}
+}
+
+// webhookReceiver records the deliveries it gets and fails the first failFirst
+type webhookReceiver struct {
+	mu        sync.Mutex
+	failFirst int
+	requests  []*http.Request
+	bodies    [][]byte
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	if len(rc.requests) <= rc.failFirst {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestDeliverWebhookRetriesAndSigns(t *testing.T) {
+	rc := &webhookReceiver{failFirst: 1}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	config := &Config{WebhookSecret: "rahasia", WebhookRetries: 3}
+	body := []byte(`{"event":"run.finished"}`)
+	if err := deliverWebhook(config, srv.URL, EventRunFinished, "abc", body); err != nil {
+		t.Fatalf("deliverWebhook: %v", err)
+	}
+
+	if len(rc.requests) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(rc.requests))
+	}
+	r := rc.requests[1]
+	if r.Header.Get(HeaderWebhookEvent) != EventRunFinished || r.Header.Get(HeaderWebhookDelivery) != "abc" {
+		t.Errorf("wrong event headers: %v", r.Header)
+	}
+	ts := r.Header.Get(HeaderWebhookTimestamp)
+	if got, want := r.Header.Get(HeaderWebhookSignature), signWebhook("rahasia", ts, body); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if string(rc.bodies[1]) != string(body) {
+		t.Errorf("body = %s", rc.bodies[1])
+	}
+}
+
+func TestDeliverWebhookGivesUp(t *testing.T) {
+	rc := &webhookReceiver{failFirst: 10}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	config := &Config{WebhookRetries: 1}
+	if err := deliverWebhook(config, srv.URL, EventTest, "abc", []byte(`{}`)); err == nil {
+		t.Fatal("expected an error after the last attempt")
+	}
+	if len(rc.requests) != 1 {
+		t.Fatalf("got %d attempts, want 1", len(rc.requests))
+	}
+	if rc.requests[0].Header.Get(HeaderWebhookSignature) != "" {
+		t.Error("signature sent without WEBHOOK_SECRET")
+	}
+}
+
+func TestNotifyWebhooksDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	var got WebhookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	config := &Config{BaseURL: "http://siakad.kampus.ac.id", Username: "admin", WebhookURLs: []string{srv.URL}, WebhookRetries: 1}
+	start := time.Now()
+	notifyWebhooks(config, EventLoginFailed, map[string]string{"error": "login gagal"})
+	if time.Since(start) > time.Second {
+		t.Fatal("notifyWebhooks waited for the receiver")
+	}
+
+	close(release)
+	if !waitWebhooks(5 * time.Second) {
+		t.Fatal("delivery did not finish")
+	}
+	if got.Event != EventLoginFailed || got.Host != "siakad.kampus.ac.id" || got.User != "admin" {
+		t.Errorf("unexpected event %+v", got)
+	}
+}